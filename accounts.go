@@ -1,7 +1,9 @@
 package coinbasev3
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -21,37 +23,72 @@ type Account struct {
 }
 
 type AccountAvailableBalance struct {
-	Value    string `json:"value"`
-	Currency string `json:"currency"`
+	Value    Decimal `json:"value"`
+	Currency string  `json:"currency"`
 }
 
 type AccountHold struct {
-	Value    string `json:"value"`
-	Currency string `json:"currency"`
+	Value    Decimal `json:"value"`
+	Currency string  `json:"currency"`
 }
 
-// ListAccounts gets a list of authenticated accounts for the current user.
+// ListAccounts gets a list of authenticated accounts for the current user. A limit of zero uses the server default of 49, and limits above the maximum of 250 are capped. Use NewAccountsPager to follow the cursor automatically.
 func (c *ApiClient) ListAccounts(limit int, cursor string) (ListAccountsData, error) {
-	// A pagination limit with default of 49 and maximum of 250.
-	if limit < 49 {
-		limit = 49
-	}
+	return c.ListAccountsWithContext(context.Background(), limit, cursor)
+}
+
+// ListAccountsWithContext is like ListAccounts but binds the request to the given context.
+func (c *ApiClient) ListAccountsWithContext(ctx context.Context, limit int, cursor string) (ListAccountsData, error) {
+	return c.QueryAccountsWithContext(ctx, ListAccountsQuery{Limit: limit, Cursor: cursor})
+}
+
+// ListAccountsQuery represents the request parameters for the QueryAccounts function.
+type ListAccountsQuery struct {
+	// Limit int The number of accounts to return. Zero uses the server default of 49, limits above the maximum of 250 are capped.
+	Limit int `json:"limit"`
+	// Cursor string Cursor used for pagination. When provided, the response returns responses after this cursor.
+	Cursor string `json:"cursor"`
+	// RetailPortfolioId string Only accounts of this portfolio are returned. Defaults to the default portfolio.
+	RetailPortfolioId string `json:"retail_portfolio_id"`
+}
+
+// BuildQueryString creates a query string from the request parameters. If no parameters are set, an empty string is returned.
+func (q ListAccountsQuery) BuildQueryString() string {
+	limit := q.Limit
 	if limit > 250 {
 		limit = 250
 	}
 
-	u := fmt.Sprintf("https://api.coinbase.com/api/v3/brokerage/accounts?limit=%d&cursor=%s", limit, cursor)
-
-	var data ListAccountsData
-	resp, err := c.client.R().SetSuccessResult(&data).Get(u)
-	if err != nil {
-		return data, err
+	sb := strings.Builder{}
+	if limit > 0 {
+		sb.WriteString(fmt.Sprintf("&limit=%d", limit))
+	}
+	if q.Cursor != "" {
+		sb.WriteString(fmt.Sprintf("&cursor=%s", q.Cursor))
+	}
+	if q.RetailPortfolioId != "" {
+		sb.WriteString(fmt.Sprintf("&retail_portfolio_id=%s", q.RetailPortfolioId))
 	}
 
-	if !resp.IsSuccessState() {
-		return data, ErrFailedToUnmarshal
+	if sb.Len() > 0 {
+		return "?" + sb.String()[1:]
 	}
+	return ""
+}
+
+// QueryAccounts is like ListAccounts but takes a ListAccountsQuery, e.g. to list the accounts of a portfolio other than the default one.
+func (c *ApiClient) QueryAccounts(q ListAccountsQuery) (ListAccountsData, error) {
+	return c.QueryAccountsWithContext(context.Background(), q)
+}
 
+// QueryAccountsWithContext is like QueryAccounts but binds the request to the given context.
+func (c *ApiClient) QueryAccountsWithContext(ctx context.Context, q ListAccountsQuery) (ListAccountsData, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/accounts%s", q.BuildQueryString()))
+
+	var data ListAccountsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
 	return data, nil
 }
 
@@ -64,18 +101,17 @@ type ListAccountsData struct {
 
 // GetAccount get a list of information about an account, given an account UUID.
 func (c *ApiClient) GetAccount(uuid string) (Account, error) {
-	u := fmt.Sprintf("https://api.coinbase.com/api/v3/brokerage/accounts/%s", uuid)
+	return c.GetAccountWithContext(context.Background(), uuid)
+}
+
+// GetAccountWithContext is like GetAccount but binds the request to the given context.
+func (c *ApiClient) GetAccountWithContext(ctx context.Context, uuid string) (Account, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/accounts/%s", uuid))
 
 	var data GetAccountData
-	resp, err := c.client.R().SetSuccessResult(&data).Get(u)
-	if err != nil {
+	if err := c.get(ctx, u, &data); err != nil {
 		return data.Account, err
 	}
-
-	if !resp.IsSuccessState() {
-		return data.Account, ErrFailedToUnmarshal
-	}
-
 	return data.Account, nil
 }
 
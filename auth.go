@@ -0,0 +1,230 @@
+package coinbasev3
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/imroc/req/v3"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPrivateKey = fmt.Errorf("invalid cdp private key")
+	ErrNoKeyName         = fmt.Errorf("no cdp key name provided")
+)
+
+// Authenticator signs outgoing REST requests and websocket subscriptions.
+type Authenticator interface {
+	// AuthenticateRequest adds the authentication headers to a REST request before it is sent.
+	AuthenticateRequest(r *req.Request) error
+	// AuthenticateChannel fills in the authentication fields of a websocket subscription message.
+	AuthenticateChannel(ch *WebsocketChannel) error
+}
+
+// HmacAuthenticator signs requests with the legacy API key and secret using HMAC-SHA256.
+type HmacAuthenticator struct {
+	apiKey    string
+	secretKey string
+}
+
+// NewHmacAuthenticator creates an authenticator for legacy Coinbase API keys.
+func NewHmacAuthenticator(apiKey, secretKey string) *HmacAuthenticator {
+	return &HmacAuthenticator{
+		apiKey:    apiKey,
+		secretKey: secretKey,
+	}
+}
+
+// AuthenticateRequest sets the CB-ACCESS-KEY, CB-ACCESS-SIGN and CB-ACCESS-TIMESTAMP headers.
+func (a *HmacAuthenticator) AuthenticateRequest(r *req.Request) error {
+	// create a secret key from: `timestamp + method + requestPath + body`
+	if r.RawURL == "" {
+		return fmt.Errorf("no path found")
+	}
+	u, err := url.Parse(r.RawURL)
+	if err != nil {
+		return err
+	}
+
+	// the v2 api signs the query string as well, the v3 api only the path
+	path := u.Path
+	if strings.HasPrefix(u.Path, "/v2/") {
+		path = u.RequestURI()
+	}
+
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	sig := fmt.Sprintf("%s%s%s%s", timestamp, r.Method, path, r.Body)
+
+	r.SetHeader("CB-ACCESS-KEY", a.apiKey)
+	r.SetHeader("CB-ACCESS-SIGN", string(SignHmacSha256(sig, a.secretKey)))
+	r.SetHeader("CB-ACCESS-TIMESTAMP", timestamp)
+	return nil
+}
+
+// AuthenticateChannel sets the api key, timestamp and signature on the subscription message.
+func (a *HmacAuthenticator) AuthenticateChannel(ch *WebsocketChannel) error {
+	ch.ApiKey = a.apiKey
+	ch.SecretKey = a.secretKey
+
+	ch.setTimestamp()
+	ch.setSignature()
+	return nil
+}
+
+// CdpAuthenticator signs requests with a Cloud Developer Platform API key by attaching a short-lived ES256 JWT.
+type CdpAuthenticator struct {
+	keyName    string
+	privateKey *ecdsa.PrivateKey
+}
+
+// cdpJwtTtl is how long a generated JWT stays valid. Coinbase rejects tokens that live longer than two minutes.
+const cdpJwtTtl = 2 * time.Minute
+
+// NewCdpAuthenticator creates an authenticator for a CDP API key. The key name has the form "organizations/{org_id}/apiKeys/{key_id}" and the private key is the PEM encoded EC key from the downloaded key file. Escaped newlines ("\n") in the PEM are accepted.
+func NewCdpAuthenticator(keyName, privateKeyPem string) (*CdpAuthenticator, error) {
+	if keyName == "" {
+		return nil, ErrNoKeyName
+	}
+
+	key, err := parseEcPrivateKey(privateKeyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CdpAuthenticator{
+		keyName:    keyName,
+		privateKey: key,
+	}, nil
+}
+
+// AuthenticateRequest sets the Authorization header to a bearer JWT scoped to the request's method, host and path.
+func (a *CdpAuthenticator) AuthenticateRequest(r *req.Request) error {
+	if r.RawURL == "" {
+		return fmt.Errorf("no path found")
+	}
+	u, err := url.Parse(r.RawURL)
+	if err != nil {
+		return err
+	}
+
+	token, err := a.BuildJwt(fmt.Sprintf("%s %s%s", r.Method, u.Host, u.Path))
+	if err != nil {
+		return err
+	}
+
+	r.SetHeader("Authorization", "Bearer "+token)
+	return nil
+}
+
+// AuthenticateChannel sets the jwt field on the subscription message.
+func (a *CdpAuthenticator) AuthenticateChannel(ch *WebsocketChannel) error {
+	token, err := a.BuildJwt("")
+	if err != nil {
+		return err
+	}
+
+	ch.Jwt = token
+	return nil
+}
+
+// BuildJwt creates a signed ES256 JWT for the key. The uri claim ("GET api.coinbase.com/api/v3/brokerage/accounts") is required for REST requests and must be empty for websocket subscriptions.
+func (a *CdpAuthenticator) BuildJwt(uri string) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	header := map[string]string{
+		"alg":   "ES256",
+		"typ":   "JWT",
+		"kid":   a.keyName,
+		"nonce": hex.EncodeToString(nonce),
+	}
+
+	now := time.Now().Unix()
+	claims := cdpClaims{
+		Sub: a.keyName,
+		Iss: "cdp",
+		Nbf: now,
+		Exp: now + int64(cdpJwtTtl.Seconds()),
+		Uri: uri,
+	}
+
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, a.privateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	// JWS expects the raw 64 byte r || s encoding rather than ASN.1.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+type cdpClaims struct {
+	Sub string `json:"sub"`
+	Iss string `json:"iss"`
+	Nbf int64  `json:"nbf"`
+	Exp int64  `json:"exp"`
+	Uri string `json:"uri,omitempty"`
+}
+
+// parseEcPrivateKey decodes a P-256 private key in SEC 1 ("EC PRIVATE KEY") or PKCS #8 ("PRIVATE KEY") form.
+func parseEcPrivateKey(privateKeyPem string) (*ecdsa.PrivateKey, error) {
+	privateKeyPem = strings.ReplaceAll(privateKeyPem, `\n`, "\n")
+
+	block, _ := pem.Decode([]byte(privateKeyPem))
+	if block == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	var key *ecdsa.PrivateKey
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		k, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPrivateKey, err)
+		}
+		key = k
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPrivateKey, err)
+		}
+		ek, ok := k.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: not an ecdsa key", ErrInvalidPrivateKey)
+		}
+		key = ek
+	default:
+		return nil, fmt.Errorf("%w: unexpected pem type %s", ErrInvalidPrivateKey, block.Type)
+	}
+
+	if key.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%w: expected a P-256 key", ErrInvalidPrivateKey)
+	}
+	return key, nil
+}
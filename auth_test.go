@@ -0,0 +1,281 @@
+package coinbasev3
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newTestCdpKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func verifyTestJwt(t *testing.T, key *ecdsa.PrivateKey, token string) (map[string]interface{}, map[string]interface{}) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected 3 jwt parts, got %d", len(parts))
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		t.Fatalf("Expected 64 byte signature, got %d (%v)", len(sig), err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Fatalf("Expected jwt signature to verify")
+	}
+
+	var header, claims map[string]interface{}
+	h, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	c, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if err := json.Unmarshal(h, &header); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	if err := json.Unmarshal(c, &claims); err != nil {
+		t.Fatalf("claims: %v", err)
+	}
+	return header, claims
+}
+
+func TestNewCdpAuthenticator_InvalidKey(t *testing.T) {
+	_, err := NewCdpAuthenticator("organizations/org/apiKeys/key", "not a pem")
+	if !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("Expected ErrInvalidPrivateKey, got %v", err)
+	}
+
+	_, pemKey := newTestCdpKey(t)
+	_, err = NewCdpAuthenticator("", pemKey)
+	if !errors.Is(err, ErrNoKeyName) {
+		t.Errorf("Expected ErrNoKeyName, got %v", err)
+	}
+}
+
+func TestNewCdpAuthenticator_EscapedNewlines(t *testing.T) {
+	_, pemKey := newTestCdpKey(t)
+	escaped := strings.ReplaceAll(pemKey, "\n", `\n`)
+
+	_, err := NewCdpAuthenticator("organizations/org/apiKeys/key", escaped)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+}
+
+func TestCdpAuthenticator_BuildJwt(t *testing.T) {
+	key, pemKey := newTestCdpKey(t)
+	keyName := "organizations/org/apiKeys/key"
+
+	auth, err := NewCdpAuthenticator(keyName, pemKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	token, err := auth.BuildJwt("GET api.coinbase.com/api/v3/brokerage/accounts")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	header, claims := verifyTestJwt(t, key, token)
+	if header["alg"] != "ES256" {
+		t.Errorf("Expected ES256, got %v", header["alg"])
+	}
+	if header["kid"] != keyName {
+		t.Errorf("Expected %s, got %v", keyName, header["kid"])
+	}
+	if header["nonce"] == "" || header["nonce"] == nil {
+		t.Errorf("Expected a nonce")
+	}
+	if claims["sub"] != keyName {
+		t.Errorf("Expected %s, got %v", keyName, claims["sub"])
+	}
+	if claims["iss"] != "cdp" {
+		t.Errorf("Expected cdp, got %v", claims["iss"])
+	}
+	if claims["uri"] != "GET api.coinbase.com/api/v3/brokerage/accounts" {
+		t.Errorf("Expected uri claim, got %v", claims["uri"])
+	}
+	if claims["exp"].(float64)-claims["nbf"].(float64) != 120 {
+		t.Errorf("Expected a 120 second lifetime, got %v", claims["exp"].(float64)-claims["nbf"].(float64))
+	}
+}
+
+func TestApiClient_CdpAuthenticator_SignsRequests(t *testing.T) {
+	key, pemKey := newTestCdpKey(t)
+	auth, err := NewCdpAuthenticator("organizations/org/apiKeys/key", pemKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	api := NewApiClientWithAuthenticator(auth)
+
+	var authHeader, accessKey string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/0000", func(request *http.Request) (*http.Response, error) {
+		authHeader = request.Header.Get("Authorization")
+		accessKey = request.Header.Get("CB-ACCESS-KEY")
+		resp := httpmock.NewStringResponse(http.StatusOK, `{"order":{"order_id":"0000"}}`)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	_, err = api.GetOrder("0000")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	if accessKey != "" {
+		t.Errorf("Expected no CB-ACCESS-KEY header, got %s", accessKey)
+	}
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		t.Fatalf("Expected bearer token, got %s", authHeader)
+	}
+
+	_, claims := verifyTestJwt(t, key, strings.TrimPrefix(authHeader, "Bearer "))
+	if claims["uri"] != "GET api.coinbase.com/api/v3/brokerage/orders/historical/0000" {
+		t.Errorf("Expected uri claim, got %v", claims["uri"])
+	}
+}
+
+func TestApiClient_HmacAuthenticator_SignsRequests(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var headers http.Header
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/0000", func(request *http.Request) (*http.Response, error) {
+		headers = request.Header.Clone()
+		resp := httpmock.NewStringResponse(http.StatusOK, `{"order":{"order_id":"0000"}}`)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	_, err := api.GetOrder("0000")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	if headers.Get("CB-ACCESS-KEY") != "api_key" {
+		t.Errorf("Expected api_key, got %s", headers.Get("CB-ACCESS-KEY"))
+	}
+
+	ts := headers.Get("CB-ACCESS-TIMESTAMP")
+	want := string(SignHmacSha256(ts+"GET/api/v3/brokerage/orders/historical/0000", "secret_key"))
+	if headers.Get("CB-ACCESS-SIGN") != want {
+		t.Errorf("Expected %s, got %s", want, headers.Get("CB-ACCESS-SIGN"))
+	}
+}
+
+func TestApiClient_HmacAuthenticator_SignsV2Query(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var headers http.Header
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/v2/accounts", "limit=1&starting_after=w-btc", func(request *http.Request) (*http.Response, error) {
+		headers = request.Header.Clone()
+		return jsonResponder(`{"pagination":{"limit":1},"data":[]}`)(request)
+	})
+
+	_, err := api.WalletListAccounts(WalletPaginationQuery{Limit: 1, StartingAfter: "w-btc"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	ts := headers.Get("CB-ACCESS-TIMESTAMP")
+	want := string(SignHmacSha256(ts+"GET/v2/accounts?limit=1&starting_after=w-btc", "secret_key"))
+	if headers.Get("CB-ACCESS-SIGN") != want {
+		t.Errorf("Expected %s, got %s", want, headers.Get("CB-ACCESS-SIGN"))
+	}
+}
+
+func TestWebsocketChannel_Marshal_Cdp(t *testing.T) {
+	key, pemKey := newTestCdpKey(t)
+	auth, err := NewCdpAuthenticator("organizations/org/apiKeys/key", pemKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	ch := NewLevel2Channel([]string{"BTC-USD"})
+	b, err := ch.marshal(auth)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if _, ok := msg["signature"]; ok {
+		t.Errorf("Expected no signature field, got %v", msg["signature"])
+	}
+
+	_, claims := verifyTestJwt(t, key, msg["jwt"].(string))
+	if _, ok := claims["uri"]; ok {
+		t.Errorf("Expected no uri claim for websocket jwt, got %v", claims["uri"])
+	}
+}
+
+func TestWebsocketChannel_Marshal_Hmac(t *testing.T) {
+	ch := NewLevel2Channel([]string{"BTC-USD", "ETH-USD"})
+	b, err := ch.marshal(NewHmacAuthenticator("api_key", "secret_key"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if _, ok := msg["jwt"]; ok {
+		t.Errorf("Expected no jwt field, got %v", msg["jwt"])
+	}
+
+	want := string(SignHmacSha256(msg["timestamp"].(string)+"l2_dataBTC-USD,ETH-USD", "secret_key"))
+	if msg["signature"] != want {
+		t.Errorf("Expected %s, got %v", want, msg["signature"])
+	}
+}
+
+func TestNewWsClient_WithAuthenticator(t *testing.T) {
+	_, pemKey := newTestCdpKey(t)
+	auth, err := NewCdpAuthenticator("organizations/org/apiKeys/key", pemKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	cl, err := NewWsClient(WsClientConfig{
+		ReadChannel:   make(chan []byte),
+		Authenticator: auth,
+	})
+	if err != nil {
+		t.Fatalf("NewWsClient: %v", err)
+	}
+	if cl.auth != auth {
+		t.Errorf("Expected the cdp authenticator to be used")
+	}
+
+	_, err = NewWsClient(WsClientConfig{ReadChannel: make(chan []byte)})
+	if !errors.Is(err, ErrNoApiKey) {
+		t.Errorf("Expected ErrNoApiKey, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package coinbasev3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownGranularity = fmt.Errorf("unknown granularity")
+	ErrInvalidCandleRange = fmt.Errorf("invalid candle range")
+)
+
+// maxCandlesPerRequest is the number of buckets Coinbase returns at most for a single candles request.
+const maxCandlesPerRequest = 300
+
+// Duration returns the length of a single bucket of the granularity, or zero when the granularity is unknown.
+func (g Granularity) Duration() time.Duration {
+	switch g {
+	case GranularityOneMin:
+		return time.Minute
+	case GranularityFiveMin:
+		return 5 * time.Minute
+	case GranularityFifteenMin:
+		return 15 * time.Minute
+	case GranularityThirtyMin:
+		return 30 * time.Minute
+	case GranularityOneHour:
+		return time.Hour
+	case GranularityTwoHour:
+		return 2 * time.Hour
+	case GranularitySixHour:
+		return 6 * time.Hour
+	case GranularityOneDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// BackfillCandles get the candles of a product between start and end, splitting the range into requests of at most 300 buckets. Up to concurrency requests run at once; zero or one fetches the chunks sequentially. The candles are de-duplicated and sorted by start time, oldest first.
+func (c *ApiClient) BackfillCandles(productId string, start, end time.Time, granularity Granularity, concurrency int) ([]ProductCandles, error) {
+	return c.BackfillCandlesWithContext(context.Background(), productId, start, end, granularity, concurrency)
+}
+
+// BackfillCandlesWithContext is like BackfillCandles but binds the requests to the given context. The first failed request cancels the others.
+func (c *ApiClient) BackfillCandlesWithContext(ctx context.Context, productId string, start, end time.Time, granularity Granularity, concurrency int) ([]ProductCandles, error) {
+	chunks, err := candleChunks(start, end, granularity)
+	if err != nil {
+		return nil, err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		byStart  = make(map[int64]ProductCandles)
+		sem      = make(chan struct{}, concurrency)
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	for _, chunk := range chunks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(chunk [2]time.Time) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			candles, err := c.GetProductCandlesWithContext(ctx, productId,
+				strconv.FormatInt(chunk[0].Unix(), 10), strconv.FormatInt(chunk[1].Unix(), 10), granularity)
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			mu.Lock()
+			for _, candle := range candles {
+				byStart[candle.Start.Unix()] = candle
+			}
+			mu.Unlock()
+		}(chunk)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// the parent context may have been cancelled before any request failed
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	candles := make([]ProductCandles, 0, len(byStart))
+	for _, candle := range byStart {
+		candles = append(candles, candle)
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Start.Before(candles[j].Start)
+	})
+	return candles, nil
+}
+
+// candleChunks splits [start, end] into inclusive ranges that each span at most maxCandlesPerRequest buckets.
+func candleChunks(start, end time.Time, granularity Granularity) ([][2]time.Time, error) {
+	bucket := granularity.Duration()
+	if bucket == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownGranularity, granularity)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: end %s is before start %s", ErrInvalidCandleRange, end, start)
+	}
+
+	var chunks [][2]time.Time
+	span := (maxCandlesPerRequest - 1) * bucket
+	for from := start; !from.After(end); from = from.Add(span + bucket) {
+		to := from.Add(span)
+		if to.After(end) {
+			to = end
+		}
+		chunks = append(chunks, [2]time.Time{from, to})
+	}
+	return chunks, nil
+}
@@ -0,0 +1,94 @@
+package coinbasev3
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCandleChunks(t *testing.T) {
+	start := time.Unix(1609459200, 0)
+	end := start.Add(999 * time.Minute)
+
+	chunks, err := candleChunks(start, end, GranularityOneMin)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("Expected 4 chunks, got %d", len(chunks))
+	}
+	if !chunks[0][1].Equal(start.Add(299*time.Minute)) || !chunks[1][0].Equal(start.Add(300*time.Minute)) {
+		t.Errorf("Unexpected chunk bounds %v %v", chunks[0], chunks[1])
+	}
+	if !chunks[3][1].Equal(end) {
+		t.Errorf("Expected last chunk to end at %s, got %s", end, chunks[3][1])
+	}
+
+	if _, err := candleChunks(start, end, GranularityUnknown); !errors.Is(err, ErrUnknownGranularity) {
+		t.Errorf("Expected ErrUnknownGranularity, got %v", err)
+	}
+	if _, err := candleChunks(end, start, GranularityOneMin); !errors.Is(err, ErrInvalidCandleRange) {
+		t.Errorf("Expected ErrInvalidCandleRange, got %v", err)
+	}
+}
+
+// candlesResponder returns a one minute candle for every bucket in the requested range, newest first like Coinbase does.
+func candlesResponder(request *http.Request) (*http.Response, error) {
+	start, _ := strconv.ParseInt(request.URL.Query().Get("start"), 10, 64)
+	end, _ := strconv.ParseInt(request.URL.Query().Get("end"), 10, 64)
+
+	var candles []string
+	for ts := end; ts >= start; ts -= 60 {
+		candles = append(candles, fmt.Sprintf(`{"start":"%d","low":"1","high":"3","open":"2","close":"2.5","volume":"10"}`, ts))
+	}
+	return jsonResponder(fmt.Sprintf(`{"candles":[%s]}`, strings.Join(candles, ",")))(request)
+}
+
+func TestApiClient_BackfillCandles(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "=~^https://api.coinbase.com/api/v3/brokerage/products/BTC-USD/candles", candlesResponder)
+	httpmock.ZeroCallCounters()
+
+	start := time.Unix(1609459200, 0)
+	end := start.Add(999 * time.Minute)
+	candles, err := api.BackfillCandles("BTC-USD", start, end, GranularityOneMin, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if n := httpmock.GetTotalCallCount(); n != 4 {
+		t.Errorf("Expected 4 requests, got %d", n)
+	}
+	if len(candles) != 1000 {
+		t.Fatalf("Expected 1000 candles, got %d", len(candles))
+	}
+	for i, candle := range candles {
+		if expected := start.Add(time.Duration(i) * time.Minute); !candle.Start.Equal(expected) {
+			t.Fatalf("Expected candle %d to start at %s, got %s", i, expected, candle.Start)
+		}
+	}
+	if candles[0].Close.String() != "2.5" || candles[0].Volume.String() != "10" {
+		t.Errorf("Unexpected candle %+v", candles[0])
+	}
+}
+
+func TestApiClient_BackfillCandles_Error(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+	api.SetRetryPolicy(RetryPolicy{})
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "=~^https://api.coinbase.com/api/v3/brokerage/products/BTC-USD/candles",
+		httpmock.NewStringResponder(http.StatusBadRequest, `{"error":"INVALID_ARGUMENT","message":"bad range"}`))
+
+	start := time.Unix(1609459200, 0)
+	_, err := api.BackfillCandles("BTC-USD", start, start.Add(999*time.Minute), GranularityOneMin, 2)
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
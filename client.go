@@ -1,17 +1,17 @@
 package coinbasev3
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
 	"github.com/imroc/req/v3"
-	"net/url"
 	"strings"
 	"time"
 )
 
 var (
 	ErrFailedToUnmarshal = fmt.Errorf("failed to unmarshal response")
+	ErrNoCredentials     = fmt.Errorf("endpoint requires authentication, but the client has no credentials")
+	ErrUnsupportedMethod = fmt.Errorf("http client does not support the request method")
 )
 
 type HttpClient interface {
@@ -20,9 +20,24 @@ type HttpClient interface {
 	GetClient() *req.Client
 }
 
+// HttpClientWithContext is an optional extension of HttpClient. When the configured HttpClient implements it, the ApiClient passes the caller's context through so deadlines and cancellation reach the underlying request.
+type HttpClientWithContext interface {
+	GetWithContext(ctx context.Context, url string) (*req.Response, error)
+	PostWithContext(ctx context.Context, url string, data []byte) (*req.Response, error)
+}
+
+// HttpClientWithPutDelete is an optional extension of HttpClient for the endpoints that use PUT and DELETE. When the configured HttpClient does not implement it, the request is made with the req.Client returned by GetClient.
+type HttpClientWithPutDelete interface {
+	PutWithContext(ctx context.Context, url string, data []byte) (*req.Response, error)
+	DeleteWithContext(ctx context.Context, url string) (*req.Response, error)
+}
+
 type ApiClient struct {
-	apiKey          string
-	secretKey       string
+	auth            Authenticator
+	limiter         *rateLimiter
+	retry           *RetryPolicy
+	previewOrders   bool
+	public          bool
 	client          *req.Client
 	httpClient      HttpClient
 	baseUrlV3       string
@@ -30,100 +45,171 @@ type ApiClient struct {
 	baseExchangeUrl string
 }
 
-// NewApiClient creates a new Coinbase API client. The API key and secret key are used to sign requests. The default timeout is 10 seconds. The default retry count is 3. The default retry backoff interval is 1 second to 5 seconds.
+// NewApiClient creates a new Coinbase API client. The API key and secret key are used to sign requests. The default timeout is 10 seconds. The default retry count is 3. The default retry backoff interval is 1 second to 5 seconds. See SetRetryPolicy to change it.
 func NewApiClient(apiKey, secretKey string, clients ...HttpClient) *ApiClient {
-	if clients != nil && len(clients) > 0 {
-		ac := &ApiClient{
-			apiKey:     apiKey,
-			secretKey:  secretKey,
-			client:     newClient(apiKey, secretKey),
-			httpClient: clients[0],
-		}
-		ac.setBaseUrls()
-		return ac
-	}
-
-	client := newClient(apiKey, secretKey)
+	return NewApiClientWithAuthenticator(NewHmacAuthenticator(apiKey, secretKey), clients...)
+}
 
+// NewApiClientWithAuthenticator creates a new Coinbase API client that signs requests with the given Authenticator, e.g. a CdpAuthenticator for Cloud Developer Platform keys.
+func NewApiClientWithAuthenticator(auth Authenticator, clients ...HttpClient) *ApiClient {
+	retry := NewRetryPolicy()
 	ac := &ApiClient{
-		apiKey:     apiKey,
-		secretKey:  secretKey,
-		client:     client,
-		httpClient: &ReqClient{client: client},
+		auth:  auth,
+		retry: &retry,
+	}
+	ac.client = ac.newClient()
+	ac.configureRetries()
+
+	if clients != nil && len(clients) > 0 {
+		ac.httpClient = clients[0]
+	} else {
+		ac.httpClient = &ReqClient{client: ac.client}
 	}
+
 	ac.setBaseUrls()
 	return ac
 }
 
-func newClient(apiKey, secretKey string) *req.Client {
+// NewPublicApiClient creates a Coinbase API client without credentials. Market data methods such as GetProduct, GetProductBook and GetProductCandles use the public /brokerage/market endpoints, and requests are never signed. Endpoints that require authentication return ErrNoCredentials.
+func NewPublicApiClient(clients ...HttpClient) *ApiClient {
+	ac := NewApiClientWithAuthenticator(nil, clients...)
+	ac.public = true
+	return ac
+}
+
+func (c *ApiClient) newClient() *req.Client {
 	client := req.C().
 		SetTimeout(time.Second * 10).
 		SetUserAgent("GoCoinbaseV3/1.0.0")
 
+	// wait for the rate limiter before signing so the signature timestamp is fresh when the request is sent
+	client.OnBeforeRequest(func(client *req.Client, req *req.Request) error {
+		if c.limiter == nil {
+			return nil
+		}
+		return c.limiter.wait(req)
+	})
+
+	client.OnBeforeRequest(func(client *req.Client, req *req.Request) error {
+		if token := twoFactorToken(req.Context()); token != "" {
+			req.SetHeader("CB-2FA-TOKEN", token)
+		}
+		return nil
+	})
+
 	// TODO: figure out how to do this where we can use PathParam, QueryParam, etc.
 	client.OnBeforeRequest(func(client *req.Client, req *req.Request) error {
-		// create a secret key from: `timestamp + method + requestPath + body`
-		path := ""
-		if req.RawURL != "" {
-			u, err := url.Parse(req.RawURL)
-			if err != nil {
-				return err
+		if c.auth == nil {
+			if isPublicEndpoint(req.RawURL) {
+				return nil
 			}
-			path = u.Path
-		} else {
-			return fmt.Errorf("no path found")
+			return ErrNoCredentials
 		}
+		return c.auth.AuthenticateRequest(req)
+	})
 
-		sig := fmt.Sprintf("%d%s%s%s", time.Now().Unix(), req.Method, path, req.Body)
-		signedSig := string(SignHmacSha256(sig, secretKey))
-
-		client.Headers.Set("CB-ACCESS-KEY", apiKey)
-		client.Headers.Set("CB-ACCESS-SIGN", signedSig)
-		client.Headers.Set("CB-ACCESS-TIMESTAMP", fmt.Sprintf("%d", time.Now().Unix()))
+	client.OnAfterResponse(func(client *req.Client, resp *req.Response) error {
+		if c.limiter != nil {
+			c.limiter.onResponse(resp)
+		}
 		return nil
 	})
 
 	return client
 }
 
-func (c *ApiClient) get(url string, out interface{}) ([]byte, error) {
-	resp, err := c.httpClient.Get(url)
+// get makes a GET request and unmarshals a successful response into out. Non-2xx responses are returned as a ResponseError.
+func (c *ApiClient) get(ctx context.Context, url string, out interface{}) error {
+	resp, err := c.httpGet(ctx, url)
+	return decodeResponse(resp, err, out)
+}
+
+// post makes a POST request and unmarshals a successful response into out. Non-2xx responses are returned as a ResponseError.
+func (c *ApiClient) post(ctx context.Context, url string, data []byte, out interface{}) error {
+	resp, err := c.httpPost(ctx, url, data)
+	return decodeResponse(resp, err, out)
+}
+
+// put makes a PUT request and unmarshals a successful response into out. Non-2xx responses are returned as a ResponseError.
+func (c *ApiClient) put(ctx context.Context, url string, data []byte, out interface{}) error {
+	resp, err := c.httpPut(ctx, url, data)
+	return decodeResponse(resp, err, out)
+}
+
+// delete makes a DELETE request and unmarshals a successful response into out. Non-2xx responses are returned as a ResponseError.
+func (c *ApiClient) delete(ctx context.Context, url string, out interface{}) error {
+	resp, err := c.httpDelete(ctx, url)
+	return decodeResponse(resp, err, out)
+}
+
+// decodeResponse turns the result of a request into either the unmarshalled body or an error.
+func decodeResponse(resp *req.Response, err error, out interface{}) error {
 	if err != nil {
-		return resp.Bytes(), err
+		return err
 	}
 
 	if !resp.IsSuccessState() {
-		return resp.Bytes(), ErrFailedToUnmarshal
+		return newResponseError(resp)
 	}
 
-	err = resp.Unmarshal(&out)
-	if err != nil {
-		return resp.Bytes(), err
+	if out == nil {
+		return nil
+	}
+	if err = resp.Unmarshal(out); err != nil {
+		return fmt.Errorf("%w: %s", ErrFailedToUnmarshal, err)
 	}
+	return nil
+}
 
-	return resp.Bytes(), nil
+// httpGet makes a GET request with the configured HttpClient, forwarding the context when the client supports it.
+func (c *ApiClient) httpGet(ctx context.Context, url string) (*req.Response, error) {
+	if hc, ok := c.httpClient.(HttpClientWithContext); ok {
+		return hc.GetWithContext(ctx, url)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.httpClient.Get(url)
 }
 
-func (c *ApiClient) post(url string, data []byte, out interface{}) ([]byte, error) {
-	resp, err := c.httpClient.Post(url, data)
-	if err != nil {
-		return resp.Bytes(), err
+// httpPost makes a POST request with the configured HttpClient, forwarding the context when the client supports it.
+func (c *ApiClient) httpPost(ctx context.Context, url string, data []byte) (*req.Response, error) {
+	if hc, ok := c.httpClient.(HttpClientWithContext); ok {
+		return hc.PostWithContext(ctx, url, data)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.httpClient.Post(url, data)
+}
 
-	if !resp.IsSuccessState() {
-		return resp.Bytes(), ErrFailedToUnmarshal
+// httpPut makes a PUT request with the configured HttpClient, falling back to its req.Client when it does not implement HttpClientWithPutDelete.
+func (c *ApiClient) httpPut(ctx context.Context, url string, data []byte) (*req.Response, error) {
+	if hc, ok := c.httpClient.(HttpClientWithPutDelete); ok {
+		return hc.PutWithContext(ctx, url, data)
+	}
+	client := c.httpClient.GetClient()
+	if client == nil {
+		return nil, fmt.Errorf("%w: PUT", ErrUnsupportedMethod)
 	}
+	return client.R().SetContext(ctx).SetBody(data).Put(url)
+}
 
-	err = resp.Unmarshal(&out)
-	if err != nil {
-		return resp.Bytes(), err
+// httpDelete makes a DELETE request with the configured HttpClient, falling back to its req.Client when it does not implement HttpClientWithPutDelete.
+func (c *ApiClient) httpDelete(ctx context.Context, url string) (*req.Response, error) {
+	if hc, ok := c.httpClient.(HttpClientWithPutDelete); ok {
+		return hc.DeleteWithContext(ctx, url)
+	}
+	client := c.httpClient.GetClient()
+	if client == nil {
+		return nil, fmt.Errorf("%w: DELETE", ErrUnsupportedMethod)
 	}
-	return resp.Bytes(), nil
+	return client.R().SetContext(ctx).Delete(url)
 }
 
 func (c *ApiClient) setBaseUrls() {
 	c.baseUrlV3 = "https://api.coinbase.com/api/v3"
-	c.baseUrlV2 = "https://api.coinbase.com/api/v2"
+	c.baseUrlV2 = "https://api.coinbase.com/v2"
 	c.baseExchangeUrl = "https://api.exchange.coinbase.com"
 }
 
@@ -146,6 +232,14 @@ func (c *ApiClient) makeV3Url(path string) string {
 	return fmt.Sprintf("%s/%s", c.baseUrlV3, path)
 }
 
+// makeMarketUrl makes the url of a market data endpoint, which is the public /brokerage/market equivalent when the client has no credentials.
+func (c *ApiClient) makeMarketUrl(path string) string {
+	if c.public {
+		path = strings.Replace(path, "/brokerage/", "/brokerage/market/", 1)
+	}
+	return c.makeV3Url(path)
+}
+
 // SetBaseUrlV2 sets the base URL for the Sign In With Coinbase APIs.
 func (c *ApiClient) SetBaseUrlV2(url string) {
 	c.baseUrlV2 = url
@@ -200,66 +294,42 @@ func (c *ReqClient) Post(url string, data []byte) (*req.Response, error) {
 	return resp, nil
 }
 
-type ResponseError struct {
-	Message       string        `json:"message"`
-	CoinbaseError CoinbaseError `json:"coinbase_error"`
-}
+// GetWithContext makes a GET request to the given URL bound to the given context.
+func (c *ReqClient) GetWithContext(ctx context.Context, url string) (*req.Response, error) {
+	resp, err := c.client.R().SetContext(ctx).Get(url)
+	if err != nil {
+		return nil, err
+	}
 
-// Implement the Error() method for MyCustomError.
-// This method makes MyCustomError satisfy the error interface.
-func (e ResponseError) Error() string {
-	return e.Message
+	return resp, nil
 }
 
-func newResponseError(res []byte) error {
-	resErr := newCoinbaseError(res)
-	return ResponseError{
-		Message:       resErr.Message,
-		CoinbaseError: resErr.CoinbaseError,
+// PostWithContext makes a POST request to the given URL bound to the given context.
+func (c *ReqClient) PostWithContext(ctx context.Context, url string, data []byte) (*req.Response, error) {
+	resp, err := c.client.R().SetContext(ctx).SetBody(data).Post(url)
+	if err != nil {
+		return nil, err
 	}
-}
 
-type CoinbaseError struct {
-	Error        string       `json:"error"`
-	Code         string       `json:"code"`
-	Message      string       `json:"message"`
-	ErrorDetails string       `json:"error_details"`
-	Details      ErrorDetails `json:"details"`
+	return resp, nil
 }
 
-func newCoinbaseError(res []byte) ResponseError {
-	var errRes CoinbaseError
-	err := json.Unmarshal(res, &errRes)
+// PutWithContext makes a PUT request to the given URL bound to the given context.
+func (c *ReqClient) PutWithContext(ctx context.Context, url string, data []byte) (*req.Response, error) {
+	resp, err := c.client.R().SetContext(ctx).SetBody(data).Put(url)
 	if err != nil {
-		return ResponseError{
-			Message:       err.Error(),
-			CoinbaseError: errRes,
-		}
-	}
-	return ResponseError{
-		Message:       errRes.Message,
-		CoinbaseError: errRes,
+		return nil, err
 	}
-}
 
-type ErrorDetail struct {
-	TypeUrl string `json:"type_url"`
-	Value   string `json:"value"`
+	return resp, nil
 }
 
-type ErrorDetails []ErrorDetail
-
-// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase returns an array of error details or a single error detail object.
-func (ed *ErrorDetails) UnmarshalJSON(data []byte) error {
-	var details []ErrorDetail
-	if err := json.Unmarshal(data, &details); err == nil {
-		*ed = details
-		return nil
-	}
-	var detail ErrorDetail
-	if err := json.Unmarshal(data, &detail); err == nil {
-		*ed = ErrorDetails{detail}
-		return nil
+// DeleteWithContext makes a DELETE request to the given URL bound to the given context.
+func (c *ReqClient) DeleteWithContext(ctx context.Context, url string) (*req.Response, error) {
+	resp, err := c.client.R().SetContext(ctx).Delete(url)
+	if err != nil {
+		return nil, err
 	}
-	return errors.New("error details should be an array or a single object")
+
+	return resp, nil
 }
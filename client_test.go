@@ -1,6 +1,14 @@
 package coinbasev3
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"github.com/imroc/req/v3"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"testing"
+	"time"
+)
 
 func TestNewApiClient(t *testing.T) {
 	api := NewApiClient("api_key", "secret_key")
@@ -15,8 +23,8 @@ func TestNewApiClient(t *testing.T) {
 	if api.baseUrlV3 != "https://api.coinbase.com/api/v3" {
 		t.Errorf("Expected base url to be https://api.coinbase.com/api/v3, got %s", api.baseUrlV3)
 	}
-	if api.baseUrlV2 != "https://api.coinbase.com/api/v2" {
-		t.Errorf("Expected base url to be https://api.coinbase.com/api/v2, got %s", api.baseUrlV2)
+	if api.baseUrlV2 != "https://api.coinbase.com/v2" {
+		t.Errorf("Expected base url to be https://api.coinbase.com/v2, got %s", api.baseUrlV2)
 	}
 	if api.baseExchangeUrl != "https://api.exchange.coinbase.com" {
 		t.Errorf("Expected base url to be https://api.exchange.coinbase.com, got %s", api.baseExchangeUrl)
@@ -37,8 +45,8 @@ func TestNewApiClient_WithCustomClient(t *testing.T) {
 	if api.baseUrlV3 != "https://api.coinbase.com/api/v3" {
 		t.Errorf("Expected base url to be https://api.coinbase.com/api/v3, got %s", api.baseUrlV3)
 	}
-	if api.baseUrlV2 != "https://api.coinbase.com/api/v2" {
-		t.Errorf("Expected base url to be https://api.coinbase.com/api/v2, got %s", api.baseUrlV2)
+	if api.baseUrlV2 != "https://api.coinbase.com/v2" {
+		t.Errorf("Expected base url to be https://api.coinbase.com/v2, got %s", api.baseUrlV2)
 	}
 	if api.baseExchangeUrl != "https://api.exchange.coinbase.com" {
 		t.Errorf("Expected base url to be https://api.exchange.coinbase.com, got %s", api.baseExchangeUrl)
@@ -112,3 +120,105 @@ func TestApiClient_SetSandboxUrls(t *testing.T) {
 		}
 	}
 }
+
+func TestApiClient_WithContext_Deadline(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/products/ETH-USD", func(request *http.Request) (*http.Response, error) {
+		if request.Header.Get("X-Slow") != "" {
+			<-request.Context().Done()
+			return nil, request.Context().Err()
+		}
+		resp := httpmock.NewStringResponse(http.StatusOK, `{"product_id":"ETH-USD"}`)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	api.client.SetCommonHeader("X-Slow", "1")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := api.GetProductWithContext(ctx, "ETH-USD")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	api.client.Headers.Del("X-Slow")
+
+	data, err := api.GetProductWithContext(context.Background(), "ETH-USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if data.ProductId != "ETH-USD" {
+		t.Errorf("Expected ETH-USD, got %s", data.ProductId)
+	}
+}
+
+func TestApiClient_WithContext_CanceledWithoutContextClient(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key", NewMockHttpClient(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := api.GetOrderWithContext(ctx, "0000-000000-000000")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// recordingHttpClient wraps a ReqClient and records the PUT and DELETE requests made through it.
+type recordingHttpClient struct {
+	*ReqClient
+	calls []string
+}
+
+func (c *recordingHttpClient) PutWithContext(ctx context.Context, url string, data []byte) (*req.Response, error) {
+	c.calls = append(c.calls, "PUT "+url)
+	return c.ReqClient.PutWithContext(ctx, url, data)
+}
+
+func (c *recordingHttpClient) DeleteWithContext(ctx context.Context, url string) (*req.Response, error) {
+	c.calls = append(c.calls, "DELETE "+url)
+	return c.ReqClient.DeleteWithContext(ctx, url)
+}
+
+func TestApiClient_PutDelete_CustomClient(t *testing.T) {
+	hc := &recordingHttpClient{ReqClient: &ReqClient{client: req.C()}}
+	api := NewApiClient("api_key", "secret_key", hc)
+
+	httpmock.ActivateNonDefault(hc.GetClient().GetClient())
+	httpmock.RegisterResponder("PUT", "https://api.coinbase.com/api/v3/brokerage/portfolios/p-1",
+		jsonResponder(`{"portfolio":{"name":"Renamed","uuid":"p-1","type":"CONSUMER"}}`))
+	httpmock.RegisterResponder("DELETE", "https://api.coinbase.com/api/v3/brokerage/portfolios/p-1", jsonResponder(`{}`))
+
+	portfolio, err := api.EditPortfolio("p-1", "Renamed")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if portfolio.Name != "Renamed" {
+		t.Errorf("Expected Renamed, got %s", portfolio.Name)
+	}
+	if err := api.DeletePortfolio("p-1"); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	expected := []string{
+		"PUT https://api.coinbase.com/api/v3/brokerage/portfolios/p-1",
+		"DELETE https://api.coinbase.com/api/v3/brokerage/portfolios/p-1",
+	}
+	if len(hc.calls) != 2 || hc.calls[0] != expected[0] || hc.calls[1] != expected[1] {
+		t.Errorf("Expected calls %v, got %v", expected, hc.calls)
+	}
+}
+
+func TestApiClient_PutDelete_WithoutReqClient(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key", NewMockHttpClient(nil))
+
+	if err := api.DeletePortfolio("p-1"); !errors.Is(err, ErrUnsupportedMethod) {
+		t.Errorf("Expected ErrUnsupportedMethod, got %v", err)
+	}
+	if _, err := api.EditPortfolio("p-1", "Renamed"); !errors.Is(err, ErrUnsupportedMethod) {
+		t.Errorf("Expected ErrUnsupportedMethod, got %v", err)
+	}
+}
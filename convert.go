@@ -0,0 +1,238 @@
+package coinbasev3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrConvertQuoteExpired = fmt.Errorf("convert quote expired")
+	ErrConvertFailed       = fmt.Errorf("convert trade failed")
+)
+
+// DefaultConvertQuoteTTL is how long Convert treats a quote as valid when no maximum age is given.
+const DefaultConvertQuoteTTL = 10 * time.Second
+
+type ConvertTradeStatus string
+
+const (
+	ConvertTradeStatusUnspecified ConvertTradeStatus = "TRADE_STATUS_UNSPECIFIED"
+	ConvertTradeStatusCreated     ConvertTradeStatus = "TRADE_STATUS_CREATED"
+	ConvertTradeStatusStarted     ConvertTradeStatus = "TRADE_STATUS_STARTED"
+	ConvertTradeStatusCompleted   ConvertTradeStatus = "TRADE_STATUS_COMPLETED"
+	ConvertTradeStatusCanceled    ConvertTradeStatus = "TRADE_STATUS_CANCELED"
+)
+
+type CreateConvertQuoteRequest struct {
+	// FromAccount string The currency or account uuid to convert from, e.g. USD.
+	FromAccount string `json:"from_account"`
+	// ToAccount string The currency or account uuid to convert to, e.g. USDC.
+	ToAccount string `json:"to_account"`
+	// Amount Decimal The amount of the FromAccount currency to convert.
+	Amount Decimal `json:"amount"`
+	// TradeIncentiveMetadata Optional. The incentive to apply to the conversion.
+	TradeIncentiveMetadata *TradeIncentiveMetadata `json:"trade_incentive_metadata,omitempty"`
+}
+
+type TradeIncentiveMetadata struct {
+	UserIncentiveId string `json:"user_incentive_id,omitempty"`
+	CodeVal         string `json:"code_val,omitempty"`
+}
+
+// CreateConvertQuote create a quote to convert between two currencies, e.g. USD to USDC. The quote is not executed until it is committed with CommitConvertTrade.
+func (c *ApiClient) CreateConvertQuote(req CreateConvertQuoteRequest) (ConvertTrade, error) {
+	return c.CreateConvertQuoteWithContext(context.Background(), req)
+}
+
+// CreateConvertQuoteWithContext is like CreateConvertQuote but binds the request to the given context.
+func (c *ApiClient) CreateConvertQuoteWithContext(ctx context.Context, req CreateConvertQuoteRequest) (ConvertTrade, error) {
+	u := c.makeV3Url("/brokerage/convert/quote")
+
+	// an uncommitted quote has no side effect
+	ctx = withRetrySafe(ctx)
+
+	var data ConvertTradeData
+	body, err := json.Marshal(req)
+	if err != nil {
+		return data.Trade, err
+	}
+
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data.Trade, err
+	}
+	return data.Trade, nil
+}
+
+// GetConvertTrade get a convert trade, given its id and the accounts it converts between.
+func (c *ApiClient) GetConvertTrade(tradeId, fromAccount, toAccount string) (ConvertTrade, error) {
+	return c.GetConvertTradeWithContext(context.Background(), tradeId, fromAccount, toAccount)
+}
+
+// GetConvertTradeWithContext is like GetConvertTrade but binds the request to the given context.
+func (c *ApiClient) GetConvertTradeWithContext(ctx context.Context, tradeId, fromAccount, toAccount string) (ConvertTrade, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/convert/trade/%s?from_account=%s&to_account=%s", tradeId, fromAccount, toAccount))
+
+	var data ConvertTradeData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Trade, err
+	}
+	return data.Trade, nil
+}
+
+// CommitConvertTrade execute a quote created by CreateConvertQuote.
+func (c *ApiClient) CommitConvertTrade(tradeId, fromAccount, toAccount string) (ConvertTrade, error) {
+	return c.CommitConvertTradeWithContext(context.Background(), tradeId, fromAccount, toAccount)
+}
+
+// CommitConvertTradeWithContext is like CommitConvertTrade but binds the request to the given context.
+func (c *ApiClient) CommitConvertTradeWithContext(ctx context.Context, tradeId, fromAccount, toAccount string) (ConvertTrade, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/convert/trade/%s", tradeId))
+
+	// a trade can only be committed once, so repeating the request cannot convert twice
+	ctx = withRetrySafe(ctx)
+
+	var data ConvertTradeData
+	body, err := json.Marshal(map[string]string{"from_account": fromAccount, "to_account": toAccount})
+	if err != nil {
+		return data.Trade, err
+	}
+
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data.Trade, err
+	}
+	return data.Trade, nil
+}
+
+// Convert quotes and commits a conversion in one go. The quote is not committed, and ErrConvertQuoteExpired is returned, when more than maxQuoteAge passed since it was requested. A maxQuoteAge of zero uses DefaultConvertQuoteTTL.
+func (c *ApiClient) Convert(req CreateConvertQuoteRequest, maxQuoteAge time.Duration) (ConvertTrade, error) {
+	return c.ConvertWithContext(context.Background(), req, maxQuoteAge)
+}
+
+// ConvertWithContext is like Convert but binds the requests to the given context.
+func (c *ApiClient) ConvertWithContext(ctx context.Context, req CreateConvertQuoteRequest, maxQuoteAge time.Duration) (ConvertTrade, error) {
+	if maxQuoteAge <= 0 {
+		maxQuoteAge = DefaultConvertQuoteTTL
+	}
+
+	requestedAt := time.Now()
+	quote, err := c.CreateConvertQuoteWithContext(ctx, req)
+	if err != nil {
+		return quote, err
+	}
+
+	if age := time.Since(requestedAt); age > maxQuoteAge {
+		return quote, fmt.Errorf("%w: quote %s is %s old", ErrConvertQuoteExpired, quote.Id, age.Round(time.Millisecond))
+	}
+
+	trade, err := c.CommitConvertTradeWithContext(ctx, quote.Id, req.FromAccount, req.ToAccount)
+	if err != nil {
+		return trade, err
+	}
+	if trade.Status == ConvertTradeStatusCanceled {
+		return trade, fmt.Errorf("%w: %s", ErrConvertFailed, trade.CancellationReason.Message)
+	}
+	return trade, nil
+}
+
+type ConvertTradeData struct {
+	Trade ConvertTrade `json:"trade"`
+}
+
+type ConvertTrade struct {
+	Id                 string                    `json:"id"`
+	Status             ConvertTradeStatus        `json:"status"`
+	UserEnteredAmount  Amount                    `json:"user_entered_amount"`
+	Amount             Amount                    `json:"amount"`
+	Subtotal           Amount                    `json:"subtotal"`
+	Total              Amount                    `json:"total"`
+	Fees               []ConvertFee              `json:"fees"`
+	TotalFee           ConvertFee                `json:"total_fee"`
+	TotalFeeWithoutTax ConvertFee                `json:"total_fee_without_tax"`
+	Source             ConvertAccount            `json:"source"`
+	Target             ConvertAccount            `json:"target"`
+	UnitPrice          ConvertUnitPrice          `json:"unit_price"`
+	UserWarnings       []ConvertWarning          `json:"user_warnings"`
+	UserReference      string                    `json:"user_reference"`
+	SourceCurrency     string                    `json:"source_currency"`
+	TargetCurrency     string                    `json:"target_currency"`
+	SourceId           string                    `json:"source_id"`
+	TargetId           string                    `json:"target_id"`
+	ExchangeRate       Amount                    `json:"exchange_rate"`
+	TaxDetails         []ConvertTaxDetail        `json:"tax_details"`
+	TradeIncentiveInfo ConvertTradeIncentiveInfo `json:"trade_incentive_info"`
+	FiatDenotedTotal   Amount                    `json:"fiat_denoted_total"`
+	CancellationReason ConvertCancellationReason `json:"cancellation_reason"`
+}
+
+type ConvertFee struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Amount      Amount `json:"amount"`
+	Label       string `json:"label"`
+}
+
+type ConvertAccount struct {
+	Type          string `json:"type"`
+	Network       string `json:"network"`
+	LedgerAccount struct {
+		AccountId string `json:"account_id"`
+		Currency  string `json:"currency"`
+	} `json:"ledger_account"`
+}
+
+type ConvertUnitPrice struct {
+	TargetToFiat   ConvertScaledAmount `json:"target_to_fiat"`
+	TargetToSource ConvertScaledAmount `json:"target_to_source"`
+	FiatToTarget   ConvertScaledAmount `json:"fiat_to_target"`
+}
+
+type ConvertScaledAmount struct {
+	Amount Amount `json:"amount"`
+	Scale  int    `json:"scale"`
+}
+
+type ConvertWarning struct {
+	Id   string `json:"id"`
+	Link struct {
+		Text string `json:"text"`
+		Url  string `json:"url"`
+	} `json:"link"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ConvertTaxDetail struct {
+	Name   string `json:"name"`
+	Amount Amount `json:"amount"`
+}
+
+type ConvertTradeIncentiveInfo struct {
+	AppliedIncentive    bool      `json:"applied_incentive"`
+	UserIncentiveId     string    `json:"user_incentive_id"`
+	CodeVal             string    `json:"code_val"`
+	EndsAt              time.Time `json:"ends_at"`
+	FeeWithoutIncentive Amount    `json:"fee_without_incentive"`
+	Redeemed            bool      `json:"redeemed"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because the end time is empty when no incentive applies.
+func (c *ConvertTradeIncentiveInfo) UnmarshalJSON(data []byte) error {
+	type alias ConvertTradeIncentiveInfo
+	aux := struct {
+		EndsAt flexTime `json:"ends_at"`
+		*alias
+	}{alias: (*alias)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.EndsAt = time.Time(aux.EndsAt)
+	return nil
+}
+
+type ConvertCancellationReason struct {
+	Message   string `json:"message"`
+	Code      string `json:"code"`
+	ErrorCode string `json:"error_code"`
+}
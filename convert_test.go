@@ -0,0 +1,97 @@
+package coinbasev3
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const convertTradeBody = `{"trade":{"id":"t-1","status":"%s","user_entered_amount":{"value":"100","currency":"USD"},"amount":{"value":"100","currency":"USDC"},"subtotal":{"value":"100","currency":"USD"},"total":{"value":"100","currency":"USD"},"fees":[{"title":"Coinbase fee","description":"","amount":{"value":"0","currency":"USD"},"label":"Fee"}],"total_fee":{"title":"Total fee","amount":{"value":"0","currency":"USD"}},"source":{"type":"LEDGER_ACCOUNT","network":"internal_retail","ledger_account":{"account_id":"a-usd","currency":"USD"}},"target":{"type":"LEDGER_ACCOUNT","network":"internal_retail","ledger_account":{"account_id":"a-usdc","currency":"USDC"}},"unit_price":{"target_to_source":{"amount":{"value":"1","currency":"USD"},"scale":2}},"user_warnings":[],"source_currency":"USD","target_currency":"USDC","exchange_rate":{"value":"1","currency":"USD"},"trade_incentive_info":{"applied_incentive":false},"cancellation_reason":{"message":"%s"}}}`
+
+func TestApiClient_CreateConvertQuote(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var body string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/convert/quote", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		body = string(b)
+		return jsonResponder(fmtConvertTrade("TRADE_STATUS_CREATED", ""))(request)
+	})
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/convert/trade/t-1", "from_account=USD&to_account=USDC",
+		jsonResponder(fmtConvertTrade("TRADE_STATUS_COMPLETED", "")))
+
+	quote, err := api.CreateConvertQuote(CreateConvertQuoteRequest{FromAccount: "USD", ToAccount: "USDC", Amount: "100"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if body != `{"from_account":"USD","to_account":"USDC","amount":"100"}` {
+		t.Errorf("Unexpected request body %s", body)
+	}
+	if quote.Id != "t-1" || quote.Status != ConvertTradeStatusCreated {
+		t.Errorf("Unexpected quote %+v", quote)
+	}
+	if quote.Total.Value != "100" || quote.ExchangeRate.Value != "1" || quote.Target.LedgerAccount.AccountId != "a-usdc" {
+		t.Errorf("Unexpected quote %+v", quote)
+	}
+	if len(quote.Fees) != 1 || !quote.Fees[0].Amount.Value.IsZero() || quote.UnitPrice.TargetToSource.Scale != 2 {
+		t.Errorf("Unexpected fees or unit price %+v", quote)
+	}
+
+	trade, err := api.GetConvertTrade("t-1", "USD", "USDC")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if trade.Status != ConvertTradeStatusCompleted {
+		t.Errorf("Expected a completed trade, got %s", trade.Status)
+	}
+}
+
+func TestApiClient_Convert(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	commitStatus, commits := "TRADE_STATUS_COMPLETED", 0
+	var commitBody string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/convert/quote",
+		jsonResponder(fmtConvertTrade("TRADE_STATUS_CREATED", "")))
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/convert/trade/t-1", func(request *http.Request) (*http.Response, error) {
+		commits++
+		b, _ := io.ReadAll(request.Body)
+		commitBody = string(b)
+		reason := ""
+		if commitStatus == "TRADE_STATUS_CANCELED" {
+			reason = "quote expired"
+		}
+		return jsonResponder(fmtConvertTrade(commitStatus, reason))(request)
+	})
+
+	req := CreateConvertQuoteRequest{FromAccount: "USD", ToAccount: "USDC", Amount: "100"}
+	trade, err := api.Convert(req, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if trade.Status != ConvertTradeStatusCompleted || commitBody != `{"from_account":"USD","to_account":"USDC"}` {
+		t.Errorf("Unexpected trade %+v for body %s", trade, commitBody)
+	}
+
+	commitStatus = "TRADE_STATUS_CANCELED"
+	if _, err := api.Convert(req, 0); !errors.Is(err, ErrConvertFailed) {
+		t.Errorf("Expected ErrConvertFailed, got %v", err)
+	}
+
+	if _, err := api.Convert(req, time.Nanosecond); !errors.Is(err, ErrConvertQuoteExpired) {
+		t.Errorf("Expected ErrConvertQuoteExpired, got %v", err)
+	}
+	if commits != 2 {
+		t.Errorf("Expected an expired quote not to be committed, got %d commits", commits)
+	}
+}
+
+func fmtConvertTrade(status, reason string) string {
+	return fmt.Sprintf(convertTradeBody, status, reason)
+}
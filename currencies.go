@@ -1,17 +1,24 @@
 package coinbasev3
 
+import "context"
+
 // GetFiatCurrencies lists known fiat currencies. Currency codes conform to the ISO 4217 standard where possible
 func (c *ApiClient) GetFiatCurrencies() (FiatCurrencies, error) {
+	return c.GetFiatCurrenciesWithContext(context.Background())
+}
+
+// GetFiatCurrenciesWithContext is like GetFiatCurrencies but binds the request to the given context.
+func (c *ApiClient) GetFiatCurrenciesWithContext(ctx context.Context) (FiatCurrencies, error) {
 	u := "https://api.coinbase.com/v2/currencies"
 
 	var fiats FiatCurrencies
-	resp, err := c.client.R().SetSuccessResult(&fiats).Get(u)
+	resp, err := c.client.R().SetContext(ctx).SetSuccessResult(&fiats).Get(u)
 	if err != nil {
 		return fiats, err
 	}
 
 	if !resp.IsSuccessState() {
-		return fiats, ErrFailedToUnmarshal
+		return fiats, newResponseError(resp)
 	}
 
 	return fiats, nil
@@ -28,16 +35,21 @@ type FiatCurrencies struct {
 
 // GetCurrencies lists known cryptocurrencies.
 func (c *ApiClient) GetCurrencies() (Currencies, error) {
+	return c.GetCurrenciesWithContext(context.Background())
+}
+
+// GetCurrenciesWithContext is like GetCurrencies but binds the request to the given context.
+func (c *ApiClient) GetCurrenciesWithContext(ctx context.Context) (Currencies, error) {
 	u := "https://api.coinbase.com/v2/currencies/crypto"
 
 	var curr Currencies
-	resp, err := c.client.R().SetSuccessResult(&curr).Get(u)
+	resp, err := c.client.R().SetContext(ctx).SetSuccessResult(&curr).Get(u)
 	if err != nil {
 		return curr, err
 	}
 
 	if !resp.IsSuccessState() {
-		return curr, ErrFailedToUnmarshal
+		return curr, newResponseError(resp)
 	}
 
 	return curr, nil
@@ -61,6 +73,11 @@ type Currencies struct {
 
 // GetExchangeRates get current exchange rates. Default base currency is USD, but it can be defined as any supported currency
 func (c *ApiClient) GetExchangeRates(currency string) (ExchangeRates, error) {
+	return c.GetExchangeRatesWithContext(context.Background(), currency)
+}
+
+// GetExchangeRatesWithContext is like GetExchangeRates but binds the request to the given context.
+func (c *ApiClient) GetExchangeRatesWithContext(ctx context.Context, currency string) (ExchangeRates, error) {
 	u := "https://api.coinbase.com/v2/exchange-rates"
 
 	if currency == "" {
@@ -69,6 +86,7 @@ func (c *ApiClient) GetExchangeRates(currency string) (ExchangeRates, error) {
 
 	var rates ExchangeRates
 	resp, err := c.client.R().
+		SetContext(ctx).
 		SetQueryParam("currency", currency).
 		SetSuccessResult(&rates).
 		Get(u)
@@ -77,7 +95,7 @@ func (c *ApiClient) GetExchangeRates(currency string) (ExchangeRates, error) {
 	}
 
 	if !resp.IsSuccessState() {
-		return rates, ErrFailedToUnmarshal
+		return rates, newResponseError(resp)
 	}
 
 	return rates, nil
@@ -0,0 +1,312 @@
+package coinbasev3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidDecimal = fmt.Errorf("invalid decimal")
+	ErrDivisionByZero = fmt.Errorf("division by zero")
+)
+
+var bigTen = big.NewInt(10)
+
+// maxDecimalExponent bounds the exponent accepted by parseDecimal. Larger exponents would expand to millions of digits on every parse.
+const maxDecimalExponent = 1000
+
+// Decimal is an arbitrary-precision decimal number as returned by the Coinbase API, e.g. "0.00012345". It is stored as the exact string that was received so JSON round-trips are lossless, and arithmetic is done on big integers so no precision is lost to floating point.
+//
+// An empty Decimal, which is what an omitted field decodes to, is treated as zero by the arithmetic methods. A malformed value is treated as zero as well; use IsValid to tell the two apart.
+type Decimal string
+
+// decimal is the parsed form of a Decimal: coef * 10^-scale.
+type decimal struct {
+	coef  *big.Int
+	scale int
+}
+
+// NewDecimal parses s into a Decimal. Plain and exponent notation are accepted, e.g. "-12.5" or "1e-8". The result is stored in plain notation, e.g. "0.00000001", since Coinbase rejects exponents in request bodies.
+func NewDecimal(s string) (Decimal, error) {
+	v, err := parseDecimal(strings.TrimSpace(s))
+	if err != nil {
+		return "", err
+	}
+	return Decimal(v.String()), nil
+}
+
+// MustDecimal is like NewDecimal but panics if s is not a valid decimal. Intended for constants.
+func MustDecimal(s string) Decimal {
+	d, err := NewDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewDecimalFromInt returns the Decimal of an integer.
+func NewDecimalFromInt(i int64) Decimal {
+	return Decimal(strconv.FormatInt(i, 10))
+}
+
+// NewDecimalFromFloat returns the Decimal of the shortest representation of f. NaN and infinities return an empty Decimal.
+func NewDecimalFromFloat(f float64) Decimal {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if _, err := parseDecimal(s); err != nil {
+		return ""
+	}
+	return Decimal(s)
+}
+
+// parseDecimal parses a decimal string into a coefficient and a non-negative scale.
+func parseDecimal(s string) (decimal, error) {
+	if s == "" {
+		return decimal{}, ErrInvalidDecimal
+	}
+
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > maxDecimalExponent || e < -maxDecimalExponent {
+			return decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+		}
+		exp = e
+		s = s[:i]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	digits := intPart + fracPart
+	unsigned := strings.TrimLeft(digits, "+-")
+	if unsigned == "" || len(digits)-len(unsigned) > 1 || strings.ContainsAny(fracPart, "+-") {
+		return decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+	}
+	for _, r := range unsigned {
+		if r < '0' || r > '9' {
+			return decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+		}
+	}
+
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+	}
+
+	d := decimal{coef: coef, scale: len(fracPart) - exp}
+	if d.scale < 0 {
+		d.coef.Mul(d.coef, pow10(-d.scale))
+		d.scale = 0
+	}
+	return d, nil
+}
+
+// pow10 returns 10^n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// rescale returns the coefficient of d at a larger scale.
+func (d decimal) rescale(scale int) *big.Int {
+	if scale <= d.scale {
+		return new(big.Int).Set(d.coef)
+	}
+	return new(big.Int).Mul(d.coef, pow10(scale-d.scale))
+}
+
+// String formats the coefficient with scale digits after the decimal point.
+func (d decimal) String() string {
+	s := new(big.Int).Abs(d.coef).String()
+	if d.scale > 0 {
+		if len(s) <= d.scale {
+			s = strings.Repeat("0", d.scale-len(s)+1) + s
+		}
+		s = s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+	}
+	if d.coef.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// value returns the parsed form of d, or zero if d is empty or malformed.
+func (d Decimal) value() decimal {
+	v, err := parseDecimal(string(d))
+	if err != nil {
+		return decimal{coef: new(big.Int)}
+	}
+	return v
+}
+
+// String returns the decimal exactly as it was received or constructed.
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// IsValid reports whether d is a well-formed decimal. An empty Decimal is not valid.
+func (d Decimal) IsValid() bool {
+	_, err := parseDecimal(string(d))
+	return err == nil
+}
+
+// IsZero reports whether d is zero.
+func (d Decimal) IsZero() bool {
+	return d.value().coef.Sign() == 0
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.value().coef.Sign()
+}
+
+// Cmp compares d and o and returns -1, 0 or 1. Trailing zeros are ignored, so "1.50" and "1.5" are equal.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := d.value(), o.value()
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return a.rescale(scale).Cmp(b.rescale(scale))
+}
+
+// Equal reports whether d and o are numerically equal.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// LessThan reports whether d < o.
+func (d Decimal) LessThan(o Decimal) bool {
+	return d.Cmp(o) < 0
+}
+
+// GreaterThan reports whether d > o.
+func (d Decimal) GreaterThan(o Decimal) bool {
+	return d.Cmp(o) > 0
+}
+
+// Add returns d + o.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b := d.value(), o.value()
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return Decimal(decimal{coef: new(big.Int).Add(a.rescale(scale), b.rescale(scale)), scale: scale}.String())
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Mul returns d * o.
+func (d Decimal) Mul(o Decimal) Decimal {
+	a, b := d.value(), o.value()
+	return Decimal(decimal{coef: new(big.Int).Mul(a.coef, b.coef), scale: a.scale + b.scale}.String())
+}
+
+// Div returns d / o truncated towards zero to the given number of decimal places.
+func (d Decimal) Div(o Decimal, places int) (Decimal, error) {
+	if places < 0 {
+		places = 0
+	}
+
+	a, b := d.value(), o.value()
+	if b.coef.Sign() == 0 {
+		return "", ErrDivisionByZero
+	}
+
+	// a/b * 10^places = (a.coef * 10^(places + b.scale - a.scale)) / b.coef
+	num, den := new(big.Int).Set(a.coef), new(big.Int).Set(b.coef)
+	if shift := places + b.scale - a.scale; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	return Decimal(decimal{coef: num.Quo(num, den), scale: places}.String()), nil
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	v := d.value()
+	return Decimal(decimal{coef: new(big.Int).Neg(v.coef), scale: v.scale}.String())
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	v := d.value()
+	return Decimal(decimal{coef: new(big.Int).Abs(v.coef), scale: v.scale}.String())
+}
+
+// RoundDown truncates d towards zero to the given number of decimal places.
+func (d Decimal) RoundDown(places int) Decimal {
+	if places < 0 {
+		places = 0
+	}
+
+	v := d.value()
+	if v.scale <= places {
+		return Decimal(v.String())
+	}
+	return Decimal(decimal{coef: new(big.Int).Quo(v.coef, pow10(v.scale-places)), scale: places}.String())
+}
+
+// RoundToIncrement truncates d towards zero to a multiple of increment, e.g. a product's base_increment or quote_increment. The result has as many decimal places as the increment. A zero or malformed increment returns d unchanged.
+func (d Decimal) RoundToIncrement(increment Decimal) Decimal {
+	inc, err := parseDecimal(string(increment))
+	if err != nil || inc.coef.Sign() == 0 {
+		return d
+	}
+
+	v := d.value()
+	scale := v.scale
+	if inc.scale > scale {
+		scale = inc.scale
+	}
+
+	step := new(big.Int).Abs(inc.rescale(scale))
+	n := new(big.Int).Quo(v.rescale(scale), step)
+	coef := n.Mul(n, step)
+	// the result is a multiple of the increment, so the extra digits are zeros
+	coef.Quo(coef, pow10(scale-inc.scale))
+	return Decimal(decimal{coef: coef, scale: inc.scale}.String())
+}
+
+// Float64 returns the nearest float64 of d. Use it for display or statistics only, never for order sizes.
+func (d Decimal) Float64() (float64, error) {
+	if _, err := parseDecimal(string(d)); err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(d), 64)
+}
+
+// UnmarshalJSON accepts both JSON strings and numbers. Numbers are kept exactly as written instead of going through float64.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*d = ""
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*d = Decimal(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDecimal, string(data))
+	}
+	*d = Decimal(n)
+	return nil
+}
@@ -0,0 +1,153 @@
+package coinbasev3
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNewDecimal(t *testing.T) {
+	valid := []string{"0", "-1", "+1", "1.5", ".5", "-0.00012345", "1e-8", "2.5E3", "123456789012345678901234567890.123456789"}
+	for _, s := range valid {
+		if _, err := NewDecimal(s); err != nil {
+			t.Errorf("Expected %s to be valid, got %s", s, err)
+		}
+	}
+
+	plain := map[string]Decimal{"1e-8": "0.00000001", "2.5E3": "2500", "+1": "1", " 1.50 ": "1.50", "-1e-3": "-0.001"}
+	for s, want := range plain {
+		if d, _ := NewDecimal(s); d != want {
+			t.Errorf("Expected %s to be %s, got %s", s, want, d)
+		}
+	}
+
+	invalid := []string{"", ".", "-", "1.2.3", "abc", "1e", "--1", "1-2", "NaN", "1e1001", "1e-1001", "1e50000000"}
+	for _, s := range invalid {
+		if _, err := NewDecimal(s); !errors.Is(err, ErrInvalidDecimal) {
+			t.Errorf("Expected %s to be invalid, got %v", s, err)
+		}
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	checks := []struct {
+		got  Decimal
+		want Decimal
+	}{
+		{Decimal("0.1").Add("0.2"), "0.3"},
+		{Decimal("1.50").Add("1"), "2.50"},
+		{Decimal("1").Sub("0.00000001"), "0.99999999"},
+		{Decimal("-2.5").Mul("0.4"), "-1.00"},
+		{Decimal("").Add("3"), "3"},
+		{Decimal("1e-8").Mul("100000000"), "1.00000000"},
+		{Decimal("-1.5").Abs(), "1.5"},
+		{Decimal("1.5").Neg(), "-1.5"},
+		{Decimal("37685.2987").RoundDown(2), "37685.29"},
+		{Decimal("-1.999").RoundDown(0), "-1"},
+		{Decimal("0.5").RoundDown(3), "0.5"},
+	}
+	for i, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%d: Expected %s, got %s", i, c.want, c.got)
+		}
+	}
+
+	q, err := Decimal("10").Div("3", 4)
+	if err != nil || q != "3.3333" {
+		t.Errorf("Expected 3.3333, got %s (%v)", q, err)
+	}
+	q, err = Decimal("0.000123").Div("0.1", 2)
+	if err != nil || q != "0.00" {
+		t.Errorf("Expected 0.00, got %s (%v)", q, err)
+	}
+	if _, err = Decimal("1").Div("0.0", 2); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("Expected ErrDivisionByZero, got %v", err)
+	}
+}
+
+func TestDecimal_Cmp(t *testing.T) {
+	if !Decimal("1.50").Equal("1.5") {
+		t.Errorf("Expected 1.50 to equal 1.5")
+	}
+	if !Decimal("0.00000001").GreaterThan("0") {
+		t.Errorf("Expected 0.00000001 > 0")
+	}
+	if !Decimal("-10").LessThan("-9.99") {
+		t.Errorf("Expected -10 < -9.99")
+	}
+	if !Decimal("").IsZero() || Decimal("").IsValid() {
+		t.Errorf("Expected an empty decimal to be zero but not valid")
+	}
+	if Decimal("-0.1").Sign() != -1 {
+		t.Errorf("Expected a negative sign")
+	}
+}
+
+func TestDecimal_RoundToIncrement(t *testing.T) {
+	checks := []struct {
+		d, inc, want Decimal
+	}{
+		{"0.123456789", "0.00000001", "0.12345678"},
+		{"2043.8961", "0.01", "2043.89"},
+		{"17", "5", "15"},
+		{"1.27", "0.25", "1.25"},
+		{"-1.27", "0.25", "-1.25"},
+		{"0.5", "1e-3", "0.500"},
+		{"1.23", "0", "1.23"},
+	}
+	for _, c := range checks {
+		if got := c.d.RoundToIncrement(c.inc); got != c.want {
+			t.Errorf("Expected %s rounded to %s to be %s, got %s", c.d, c.inc, c.want, got)
+		}
+	}
+
+	p := Product{BaseIncrement: "0.00000001", QuoteIncrement: "0.01"}
+	if got := p.RoundPrice("37685.299"); got != "37685.29" {
+		t.Errorf("Expected 37685.29, got %s", got)
+	}
+	p.PriceIncrement = "0.5"
+	if got := p.RoundPrice("37685.299"); got != "37685.0" {
+		t.Errorf("Expected 37685.0, got %s", got)
+	}
+	if got := p.RoundBaseSize("0.123456789"); got != "0.12345678" {
+		t.Errorf("Expected 0.12345678, got %s", got)
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var v struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+		C Decimal `json:"c"`
+		D Decimal `json:"d,omitempty"`
+	}
+
+	// numbers are kept exactly as written, beyond float64 precision
+	if err := json.Unmarshal([]byte(`{"a":"0.10000000000000000001","b":12345678901234567890.5,"c":null}`), &v); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if v.A != "0.10000000000000000001" || v.B != "12345678901234567890.5" || v.C != "" {
+		t.Errorf("Unexpected decode %+v", v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if string(b) != `{"a":"0.10000000000000000001","b":"12345678901234567890.5","c":""}` {
+		t.Errorf("Unexpected encode %s", b)
+	}
+
+	if err = json.Unmarshal([]byte(`{"a":true}`), &v); !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("Expected ErrInvalidDecimal, got %v", err)
+	}
+}
+
+func TestNewDecimalFromFloat(t *testing.T) {
+	if d := NewDecimalFromFloat(0.1); d != "0.1" {
+		t.Errorf("Expected 0.1, got %s", d)
+	}
+	if d := NewDecimalFromInt(-42); d != "-42" {
+		t.Errorf("Expected -42, got %s", d)
+	}
+}
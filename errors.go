@@ -0,0 +1,243 @@
+package coinbasev3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/imroc/req/v3"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Sentinel errors matched by ResponseError through errors.Is, e.g. errors.Is(err, coinbasev3.ErrNotFound).
+var (
+	ErrBadRequest        = fmt.Errorf("bad request")
+	ErrUnauthorized      = fmt.Errorf("unauthorized")
+	ErrForbidden         = fmt.Errorf("forbidden")
+	ErrNotFound          = fmt.Errorf("not found")
+	ErrRateLimited       = fmt.Errorf("rate limited")
+	ErrInsufficientFunds = fmt.Errorf("insufficient funds")
+	ErrServerError       = fmt.Errorf("server error")
+	ErrTwoFactorRequired = fmt.Errorf("two factor authentication required")
+)
+
+// maxErrorBodyMessage caps how much of a non-JSON body is used as the error message.
+const maxErrorBodyMessage = 200
+
+// ResponseError is returned by every endpoint when Coinbase responds with a non-2xx status, or reports a failure in the body of a successful response such as a rejected order.
+//
+// Use errors.As to access it, or errors.Is with one of the sentinel errors and the Is* predicates to classify it.
+type ResponseError struct {
+	Message       string        `json:"message"`
+	CoinbaseError CoinbaseError `json:"coinbase_error"`
+	StatusCode    int           `json:"status_code"` // HTTP status code of the response
+	Code          string        `json:"code"`        // Coinbase error code, e.g. NOT_FOUND, PERMISSION_DENIED or INSUFFICIENT_FUND
+	Method        string        `json:"method"`      // HTTP method of the request
+	Path          string        `json:"path"`        // path of the request, without the query string
+	Body          []byte        `json:"-"`           // raw response body
+}
+
+// Error implements the error interface.
+func (e ResponseError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Code
+	}
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	if e.Method == "" && e.Path == "" {
+		return fmt.Sprintf("coinbase: %d %s", e.StatusCode, msg)
+	}
+	return fmt.Sprintf("coinbase: %s %s: %d %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// Is reports whether the error matches one of the sentinel errors, based on the status code and the Coinbase error code.
+func (e ResponseError) Is(target error) bool {
+	code := strings.ToUpper(e.Code)
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || code == "INVALID_ARGUMENT"
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || code == "UNAUTHENTICATED" || code == "AUTHENTICATION_ERROR" || code == "INVALID_TOKEN" || code == "EXPIRED_TOKEN"
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden || code == "PERMISSION_DENIED" || code == "INVALID_SCOPE"
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || code == "NOT_FOUND"
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || code == "RESOURCE_EXHAUSTED" || code == "RATE_LIMIT_EXCEEDED"
+	case ErrInsufficientFunds:
+		return strings.Contains(code, "INSUFFICIENT_FUND")
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	case ErrTwoFactorRequired:
+		return code == "TWO_FACTOR_REQUIRED"
+	}
+	return false
+}
+
+// newResponseError builds a ResponseError from a response with a non-2xx status.
+func newResponseError(resp *req.Response) error {
+	e := ResponseError{
+		StatusCode: resp.StatusCode,
+		Body:       resp.Bytes(),
+	}
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		if resp.Request.URL != nil {
+			e.Path = resp.Request.URL.Path
+		}
+	}
+
+	if err := json.Unmarshal(e.Body, &e.CoinbaseError); err == nil {
+		e.Message = e.CoinbaseError.message()
+		e.Code = e.CoinbaseError.code()
+	} else {
+		// e.g. an HTML page from a proxy or load balancer
+		e.Message = strings.TrimSpace(string(e.Body))
+		if len(e.Message) > maxErrorBodyMessage {
+			e.Message = e.Message[:maxErrorBodyMessage]
+		}
+	}
+	return e
+}
+
+// CoinbaseError is the error body returned by Coinbase. The Advanced Trade API fills in Error, Code and Message, the v2 API fills in Errors.
+type CoinbaseError struct {
+	Error        string       `json:"error"`
+	Code         ErrorCode    `json:"code"`
+	Message      string       `json:"message"`
+	ErrorDetails string       `json:"error_details"`
+	Details      ErrorDetails `json:"details"`
+	Errors       []V2Error    `json:"errors"`
+}
+
+// V2Error is a single error of a v2 API error body.
+type V2Error struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+	Url     string `json:"url"`
+}
+
+// code returns the most specific error code in the body.
+func (e CoinbaseError) code() string {
+	if e.Error != "" {
+		return e.Error
+	}
+	if len(e.Errors) > 0 && e.Errors[0].Id != "" {
+		return e.Errors[0].Id
+	}
+	if _, err := strconv.Atoi(string(e.Code)); err != nil {
+		return string(e.Code)
+	}
+	return ""
+}
+
+// message returns the most descriptive error message in the body.
+func (e CoinbaseError) message() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if len(e.Errors) > 0 {
+		return e.Errors[0].Message
+	}
+	return e.ErrorDetails
+}
+
+// ErrorCode is the code of a CoinbaseError. The Advanced Trade API sends a numeric gRPC code, other APIs send a string.
+type ErrorCode string
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase returns the code as a number or a string.
+func (c *ErrorCode) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = ErrorCode(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return errors.New("error code should be a string or a number")
+	}
+	*c = ErrorCode(n)
+	return nil
+}
+
+type ErrorDetail struct {
+	TypeUrl string `json:"type_url"`
+	Value   string `json:"value"`
+}
+
+type ErrorDetails []ErrorDetail
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase returns an array of error details or a single error detail object.
+func (ed *ErrorDetails) UnmarshalJSON(data []byte) error {
+	var details []ErrorDetail
+	if err := json.Unmarshal(data, &details); err == nil {
+		*ed = details
+		return nil
+	}
+	var detail ErrorDetail
+	if err := json.Unmarshal(data, &detail); err == nil {
+		*ed = ErrorDetails{detail}
+		return nil
+	}
+	return errors.New("error details should be an array or a single object")
+}
+
+// IsRateLimited reports whether the request was throttled, either by Coinbase or by the client side rate limiter.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrRateLimitExceeded)
+}
+
+// IsUnauthorized reports whether the credentials were missing, invalid or expired.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden reports whether the credentials lack the permission for the request.
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsNotFound reports whether the requested resource does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsInsufficientFunds reports whether the account balance was too low for the request.
+func IsInsufficientFunds(err error) bool {
+	return errors.Is(err, ErrInsufficientFunds)
+}
+
+// IsTwoFactorRequired reports whether the request has to be repeated with a two factor token, e.g. in WalletSendMoneyRequest.TwoFactorToken.
+func IsTwoFactorRequired(err error) bool {
+	return errors.Is(err, ErrTwoFactorRequired)
+}
+
+// IsRetryable reports whether the request may succeed if it is repeated: throttled requests, 5xx responses and network errors. Cancelled requests are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if IsRateLimited(err) {
+		return true
+	}
+
+	var respErr ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
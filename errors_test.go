@@ -0,0 +1,140 @@
+package coinbasev3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestApiClient_GetProduct_NotFound(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+	api.DisableRetries()
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/products/FOO-USD", func(request *http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(http.StatusNotFound, `{"error":"NOT_FOUND","code":5,"message":"product not found","details":[]}`), nil
+	})
+
+	_, err := api.GetProduct("FOO-USD")
+	if !IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+
+	var respErr ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatalf("Expected a ResponseError, got %T", err)
+	}
+	if respErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", respErr.StatusCode)
+	}
+	if respErr.Code != "NOT_FOUND" {
+		t.Errorf("Expected NOT_FOUND, got %s", respErr.Code)
+	}
+	if respErr.CoinbaseError.Code != "5" {
+		t.Errorf("Expected code 5, got %s", respErr.CoinbaseError.Code)
+	}
+	if respErr.Method != http.MethodGet || respErr.Path != "/api/v3/brokerage/products/FOO-USD" {
+		t.Errorf("Unexpected request %s %s", respErr.Method, respErr.Path)
+	}
+	if respErr.Message != "product not found" || len(respErr.Body) == 0 {
+		t.Errorf("Expected the message and body to be kept, got %q", respErr.Message)
+	}
+	if err.Error() != "coinbase: GET /api/v3/brokerage/products/FOO-USD: 404 product not found" {
+		t.Errorf("Unexpected error message %s", err)
+	}
+	if IsRetryable(err) || IsUnauthorized(err) {
+		t.Errorf("Expected a not found error to only match ErrNotFound")
+	}
+}
+
+func TestApiClient_ListAccounts_Errors(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+	api.DisableRetries()
+
+	status := http.StatusUnauthorized
+	body := `{"error":"unauthorized","error_details":"invalid signature"}`
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/accounts", func(request *http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(status, body), nil
+	})
+
+	_, err := api.ListAccounts(0, "")
+	if !IsUnauthorized(err) || IsForbidden(err) {
+		t.Errorf("Expected an unauthorized error, got %v", err)
+	}
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected errors.Is to match ErrUnauthorized")
+	}
+
+	status, body = http.StatusTooManyRequests, `{"error":"RESOURCE_EXHAUSTED","message":"too many requests"}`
+	_, err = api.ListAccounts(0, "")
+	if !IsRateLimited(err) || !IsRetryable(err) {
+		t.Errorf("Expected a retryable rate limit error, got %v", err)
+	}
+
+	status, body = http.StatusBadGateway, `<html><body>502 Bad Gateway</body></html>`
+	_, err = api.ListAccounts(0, "")
+	if !errors.Is(err, ErrServerError) || !IsRetryable(err) {
+		t.Errorf("Expected a retryable server error, got %v", err)
+	}
+	var respErr ResponseError
+	if errors.As(err, &respErr) && respErr.Message != body {
+		t.Errorf("Expected the raw body as the message, got %s", respErr.Message)
+	}
+}
+
+func TestApiClient_CreateOrder_InsufficientFunds(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders", func(request *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(http.StatusOK, `{"success":false,"failure_reason":"UNKNOWN_FAILURE_REASON","order_id":"","error_response":{"error":"INSUFFICIENT_FUND","message":"Insufficient balance in source account","error_details":"","preview_failure_reason":"PREVIEW_INSUFFICIENT_FUND"}}`)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	data, err := api.CreateOrder(CreateOrderRequest{ProductID: "BTC-USD", Side: OrderSideBuy})
+	if !IsInsufficientFunds(err) {
+		t.Fatalf("Expected an insufficient funds error, got %v", err)
+	}
+	if data.ErrorResponse.PreviewFailureReason != "PREVIEW_INSUFFICIENT_FUND" {
+		t.Errorf("Expected the response data to be returned with the error")
+	}
+	if err.Error() != "coinbase: POST /api/v3/brokerage/orders: 200 Insufficient balance in source account" {
+		t.Errorf("Unexpected error message %s", err)
+	}
+}
+
+func TestCoinbaseError_V2(t *testing.T) {
+	err := ResponseError{StatusCode: http.StatusNotFound, CoinbaseError: CoinbaseError{Errors: []V2Error{{Id: "not_found", Message: "Not found"}}}}
+	if err.CoinbaseError.code() != "not_found" || err.CoinbaseError.message() != "Not found" {
+		t.Errorf("Expected the v2 error to be used")
+	}
+	if !IsNotFound(err) {
+		t.Errorf("Expected a not found error")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	checks := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{ErrRateLimitExceeded, true},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{fmt.Errorf("wrapped: %w", ResponseError{StatusCode: http.StatusServiceUnavailable}), true},
+		{ResponseError{StatusCode: http.StatusBadRequest}, false},
+		{errors.New("something else"), false},
+	}
+	for _, c := range checks {
+		if IsRetryable(c.err) != c.want {
+			t.Errorf("Expected IsRetryable(%v) to be %t", c.err, c.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package coinbasev3
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -34,6 +35,11 @@ type TransactionSummaryRequest struct {
 
 // GetTransactionSummary get a summary of transactions with fee tiers, total volume, and fees.
 func (c *ApiClient) GetTransactionSummary(req TransactionSummaryRequest) (TransactionSummaryData, error) {
+	return c.GetTransactionSummaryWithContext(context.Background(), req)
+}
+
+// GetTransactionSummaryWithContext is like GetTransactionSummary but binds the request to the given context.
+func (c *ApiClient) GetTransactionSummaryWithContext(ctx context.Context, req TransactionSummaryRequest) (TransactionSummaryData, error) {
 	sb := strings.Builder{}
 	if req.StartDate != "" {
 		sb.WriteString(fmt.Sprintf("&start_date=%s", req.StartDate))
@@ -58,39 +64,39 @@ func (c *ApiClient) GetTransactionSummary(req TransactionSummaryRequest) (Transa
 	}
 	u := c.makeV3Url(fmt.Sprintf("/brokerage/transaction_summary%s", query))
 	var data TransactionSummaryData
-	if res, err := c.get(u, &data); err != nil {
-		return data, newResponseError(res)
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
 	}
 	return data, nil
 }
 
 type TransactionSummaryData struct {
-	TotalVolume             int                 `json:"total_volume"`
-	TotalFees               int                 `json:"total_fees"`
+	TotalVolume             Decimal             `json:"total_volume"`
+	TotalFees               Decimal             `json:"total_fees"`
 	FeeTier                 FeeTier             `json:"fee_tier"`
 	MarginRate              MarginRate          `json:"margin_rate"`
 	GoodsAndServicesTax     GoodsAndServicesTax `json:"goods_and_services_tax"`
-	AdvancedTradeOnlyVolume int                 `json:"advanced_trade_only_volume"`
-	AdvancedTradeOnlyFees   int                 `json:"advanced_trade_only_fees"`
-	CoinbaseProVolume       int                 `json:"coinbase_pro_volume"`
-	CoinbaseProFees         int                 `json:"coinbase_pro_fees"`
+	AdvancedTradeOnlyVolume Decimal             `json:"advanced_trade_only_volume"`
+	AdvancedTradeOnlyFees   Decimal             `json:"advanced_trade_only_fees"`
+	CoinbaseProVolume       Decimal             `json:"coinbase_pro_volume"`
+	CoinbaseProFees         Decimal             `json:"coinbase_pro_fees"`
 }
 
 type FeeTier struct {
-	PricingTier  string `json:"pricing_tier"`
-	UsdFrom      string `json:"usd_from"`
-	UsdTo        string `json:"usd_to"`
-	TakerFeeRate string `json:"taker_fee_rate"`
-	MakerFeeRate string `json:"maker_fee_rate"`
-	AopFrom      string `json:"aop_from"`
-	AopTo        string `json:"aop_to"`
+	PricingTier  string  `json:"pricing_tier"`
+	UsdFrom      Decimal `json:"usd_from"`
+	UsdTo        Decimal `json:"usd_to"`
+	TakerFeeRate Decimal `json:"taker_fee_rate"`
+	MakerFeeRate Decimal `json:"maker_fee_rate"`
+	AopFrom      Decimal `json:"aop_from"`
+	AopTo        Decimal `json:"aop_to"`
 }
 
 type MarginRate struct {
-	Value string `json:"value"`
+	Value Decimal `json:"value"`
 }
 
 type GoodsAndServicesTax struct {
-	Rate string `json:"rate"`
-	Type string `json:"type"`
+	Rate Decimal `json:"rate"`
+	Type string  `json:"type"`
 }
@@ -22,8 +22,8 @@ func TestApiClient_GetTransactionSummary(t *testing.T) {
 		t.Fatalf("Expected no error, got %s", err)
 	}
 
-	if data.TotalVolume != 0 {
-		t.Fatalf("Expected TotalVolume to be 0, got %d", data.TotalVolume)
+	if !data.TotalVolume.IsZero() {
+		t.Fatalf("Expected TotalVolume to be 0, got %s", data.TotalVolume)
 	}
 
 	if data.FeeTier.MakerFeeRate != "0.006" {
@@ -0,0 +1,164 @@
+package coinbasev3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCancelFailed    = fmt.Errorf("failed to cancel order")
+	ErrCancelPending   = fmt.Errorf("order cancellation still pending")
+	ErrAccountNotFound = fmt.Errorf("account not found")
+)
+
+const (
+	maxCancelBatch = 100 // number of order ids Coinbase accepts in a single batch cancel request
+	maxCancelPolls = 40  // number of times the cancelled orders are checked before giving up
+)
+
+// cancelPollInterval is the pause between checks of the cancelled orders.
+var cancelPollInterval = 250 * time.Millisecond
+
+// FlattenResult is the outcome of FlattenProduct. Order is nil when the available balance was below the product's minimum size, so nothing was sold.
+type FlattenResult struct {
+	CancelledOrderIds []string
+	Size              Decimal
+	Order             *CreateOrderData
+}
+
+// FlattenProduct cancels every open order of a spot product and market sells the available base balance, rounded down to the product's base increment. Nothing is sold when the balance is below the product's minimum size.
+//
+// A successful cancel request does not mean the order is gone yet, so the cancelled orders are polled until they reach a terminal status before the balance is read. ErrCancelPending is returned if that takes longer than about 10 seconds.
+func (c *ApiClient) FlattenProduct(productId string) (FlattenResult, error) {
+	return c.FlattenProductWithContext(context.Background(), productId)
+}
+
+// FlattenProductWithContext is like FlattenProduct but binds the requests to the given context.
+func (c *ApiClient) FlattenProductWithContext(ctx context.Context, productId string) (FlattenResult, error) {
+	var result FlattenResult
+
+	product, err := c.GetProductWithContext(ctx, productId)
+	if err != nil {
+		return result, err
+	}
+
+	result.CancelledOrderIds, err = c.cancelOpenOrders(ctx, productId)
+	if err != nil {
+		return result, err
+	}
+
+	// the funds held by an order are released once it reaches a terminal status
+	if err := c.awaitOrdersDone(ctx, result.CancelledOrderIds); err != nil {
+		return result, err
+	}
+
+	account, err := c.findAccount(ctx, product.BaseCurrencyId)
+	if err != nil {
+		return result, err
+	}
+
+	result.Size = product.RoundBaseSize(account.AvailableBalance.Value)
+	if result.Size.IsZero() || result.Size.LessThan(product.BaseMinSize) {
+		return result, nil
+	}
+
+	req, err := MarketSell(productId, result.Size).BuildFor(product)
+	if err != nil {
+		return result, err
+	}
+	order, err := c.CreateOrderWithContext(ctx, req)
+	if err != nil {
+		return result, err
+	}
+	result.Order = &order
+	return result, nil
+}
+
+// cancelOpenOrders cancels every open order of a product and returns the ids of the cancelled orders.
+func (c *ApiClient) cancelOpenOrders(ctx context.Context, productId string) ([]string, error) {
+	var ids []string
+	pager := c.NewOrdersPager(ListOrdersQuery{ProductId: productId, OrderStatus: []string{"OPEN"}})
+	for pager.Next(ctx) {
+		ids = append(ids, pager.Item().OrderId)
+	}
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+
+	var cancelled []string
+	for start := 0; start < len(ids); start += maxCancelBatch {
+		end := start + maxCancelBatch
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		data, err := c.CancelOrdersWithContext(ctx, ids[start:end])
+		if err != nil {
+			return cancelled, err
+		}
+		for _, r := range data.Results {
+			if !r.Success {
+				return cancelled, fmt.Errorf("%w: %s: %s", ErrCancelFailed, r.OrderId, r.FailureReason)
+			}
+			cancelled = append(cancelled, r.OrderId)
+		}
+	}
+	return cancelled, nil
+}
+
+// awaitOrdersDone polls the orders until every one of them reached a terminal status.
+func (c *ApiClient) awaitOrdersDone(ctx context.Context, ids []string) error {
+	pending := ids
+	for poll := 1; len(pending) > 0; poll++ {
+		var open []string
+		for _, id := range pending {
+			order, err := c.GetOrderWithContext(ctx, id)
+			if err != nil {
+				return err
+			}
+			if !isTerminalOrderStatus(order.Status) {
+				open = append(open, id)
+			}
+		}
+		if len(open) == 0 {
+			return nil
+		}
+		if poll >= maxCancelPolls {
+			return fmt.Errorf("%w: %s", ErrCancelPending, strings.Join(open, ", "))
+		}
+		pending = open
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cancelPollInterval):
+		}
+	}
+	return nil
+}
+
+// isTerminalOrderStatus reports whether an order with the status can no longer fill and holds no funds.
+func isTerminalOrderStatus(status string) bool {
+	switch status {
+	case "FILLED", "CANCELLED", "EXPIRED", "FAILED":
+		return true
+	}
+	return false
+}
+
+// findAccount returns the account that holds the given currency.
+func (c *ApiClient) findAccount(ctx context.Context, currency string) (Account, error) {
+	pager := c.NewAccountsPager(250)
+	for pager.Next(ctx) {
+		if account := pager.Item(); account.Currency == currency {
+			pager.Stop()
+			return account, nil
+		}
+	}
+	if err := pager.Err(); err != nil {
+		return Account{}, err
+	}
+	return Account{}, fmt.Errorf("%w: %s", ErrAccountNotFound, currency)
+}
@@ -0,0 +1,152 @@
+package coinbasev3
+
+import (
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func registerFlattenResponders(available string) (cancelBody, orderBody *string) {
+	cancelBody, orderBody = new(string), new(string)
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/products/BTC-USD",
+		jsonResponder(`{"product_id":"BTC-USD","price":"40000","base_increment":"0.00000001","quote_increment":"0.01","base_min_size":"0.0001","base_max_size":"1000","quote_min_size":"1","quote_max_size":"1000000","base_currency_id":"BTC","quote_currency_id":"USD","status":"online"}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/batch", "product_id=BTC-USD&order_status=OPEN",
+		jsonResponder(`{"orders":[{"order_id":"o-1","product_id":"BTC-USD","status":"OPEN"},{"order_id":"o-2","product_id":"BTC-USD","status":"OPEN"}],"has_next":false}`))
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders/batch_cancel", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		*cancelBody = string(b)
+		return jsonResponder(`{"results":[{"success":true,"order_id":"o-1"},{"success":true,"order_id":"o-2"}]}`)(request)
+	})
+	for _, id := range []string{"o-1", "o-2"} {
+		httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/"+id,
+			jsonResponder(`{"order":{"order_id":"`+id+`","product_id":"BTC-USD","status":"CANCELLED"}}`))
+	}
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/accounts", "limit=250",
+		jsonResponder(`{"accounts":[{"uuid":"a-usd","currency":"USD","available_balance":{"value":"100","currency":"USD"}},{"uuid":"a-btc","currency":"BTC","available_balance":{"value":"`+available+`","currency":"BTC"}}],"has_next":false}`))
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		*orderBody = string(b)
+		return jsonResponder(`{"success":true,"order_id":"o-3"}`)(request)
+	})
+	return cancelBody, orderBody
+}
+
+func TestApiClient_FlattenProduct(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	cancelBody, orderBody := registerFlattenResponders("0.123456789")
+
+	result, err := api.FlattenProduct("BTC-USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if *cancelBody != `{"order_ids":["o-1","o-2"]}` || len(result.CancelledOrderIds) != 2 {
+		t.Errorf("Unexpected cancel request %s", *cancelBody)
+	}
+	if result.Size != "0.12345678" {
+		t.Errorf("Expected the size to be rounded down to 0.12345678, got %s", result.Size)
+	}
+	if result.Order == nil || result.Order.OrderId != "o-3" {
+		t.Fatalf("Expected an order to be placed, got %+v", result.Order)
+	}
+	if !strings.Contains(*orderBody, `"side":"SELL","order_configuration":{"market_market_ioc":{"base_size":"0.12345678"}}`) {
+		t.Errorf("Unexpected order request %s", *orderBody)
+	}
+}
+
+func TestApiClient_FlattenProduct_BelowMinSize(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	_, orderBody := registerFlattenResponders("0.00009")
+
+	result, err := api.FlattenProduct("BTC-USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if result.Order != nil || *orderBody != "" {
+		t.Errorf("Expected no order to be placed, got %s", *orderBody)
+	}
+	if len(result.CancelledOrderIds) != 2 {
+		t.Errorf("Expected the open orders to be cancelled, got %v", result.CancelledOrderIds)
+	}
+}
+
+func TestApiClient_FlattenProduct_CancelFailed(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	_, orderBody := registerFlattenResponders("1")
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders/batch_cancel",
+		jsonResponder(`{"results":[{"success":true,"order_id":"o-1"},{"success":false,"failure_reason":"UNKNOWN_CANCEL_FAILURE_REASON","order_id":"o-2"}]}`))
+
+	_, err := api.FlattenProduct("BTC-USD")
+	if !errors.Is(err, ErrCancelFailed) {
+		t.Fatalf("Expected ErrCancelFailed, got %v", err)
+	}
+	if *orderBody != "" {
+		t.Errorf("Expected no order to be placed, got %s", *orderBody)
+	}
+}
+
+func TestApiClient_FlattenProduct_AwaitsCancel(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+	cancelPollInterval = time.Millisecond
+	defer func() { cancelPollInterval = 250 * time.Millisecond }()
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	_, orderBody := registerFlattenResponders("0.5")
+
+	// the hold of o-2 is released only once the order is reported cancelled
+	polls, released := 0, false
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/o-2", func(request *http.Request) (*http.Response, error) {
+		polls++
+		status := "CANCEL_QUEUED"
+		if polls >= 3 {
+			status, released = "CANCELLED", true
+		}
+		return jsonResponder(`{"order":{"order_id":"o-2","product_id":"BTC-USD","status":"` + status + `"}}`)(request)
+	})
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/accounts", "limit=250", func(request *http.Request) (*http.Response, error) {
+		available := "0.00005"
+		if released {
+			available = "0.5"
+		}
+		return jsonResponder(`{"accounts":[{"uuid":"a-btc","currency":"BTC","available_balance":{"value":"` + available + `","currency":"BTC"}}],"has_next":false}`)(request)
+	})
+
+	result, err := api.FlattenProduct("BTC-USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if polls != 3 {
+		t.Errorf("Expected o-2 to be polled 3 times, got %d", polls)
+	}
+	if result.Size != "0.50000000" || !strings.Contains(*orderBody, `"base_size":"0.50000000"`) {
+		t.Errorf("Expected the released balance to be sold, got %s", *orderBody)
+	}
+}
+
+func TestApiClient_FlattenProduct_CancelPending(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+	cancelPollInterval = time.Millisecond
+	defer func() { cancelPollInterval = 250 * time.Millisecond }()
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	_, orderBody := registerFlattenResponders("0.5")
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/o-2",
+		jsonResponder(`{"order":{"order_id":"o-2","product_id":"BTC-USD","status":"OPEN"}}`))
+
+	_, err := api.FlattenProduct("BTC-USD")
+	if !errors.Is(err, ErrCancelPending) {
+		t.Fatalf("Expected ErrCancelPending, got %v", err)
+	}
+	if *orderBody != "" {
+		t.Errorf("Expected no order to be placed, got %s", *orderBody)
+	}
+}
@@ -0,0 +1,320 @@
+package coinbasev3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type FuturesPositionSide string
+
+const (
+	FuturesPositionSideUnknown FuturesPositionSide = "UNKNOWN"
+	FuturesPositionSideLong    FuturesPositionSide = "LONG"
+	FuturesPositionSideShort   FuturesPositionSide = "SHORT"
+)
+
+type FuturesSweepStatus string
+
+const (
+	FuturesSweepStatusUnknown    FuturesSweepStatus = "UNKNOWN_FCM_SWEEP_STATUS"
+	FuturesSweepStatusPending    FuturesSweepStatus = "PENDING"
+	FuturesSweepStatusProcessing FuturesSweepStatus = "PROCESSING"
+)
+
+type IntradayMarginSetting string
+
+const (
+	IntradayMarginSettingUnspecified IntradayMarginSetting = "INTRADAY_MARGIN_SETTING_UNSPECIFIED"
+	IntradayMarginSettingStandard    IntradayMarginSetting = "INTRADAY_MARGIN_SETTING_STANDARD"
+	IntradayMarginSettingIntraday    IntradayMarginSetting = "INTRADAY_MARGIN_SETTING_INTRADAY"
+)
+
+type MarginProfileType string
+
+const (
+	MarginProfileTypeRetailRegular         MarginProfileType = "MARGIN_PROFILE_TYPE_RETAIL_REGULAR"
+	MarginProfileTypeRetailIntradayMargin1 MarginProfileType = "MARGIN_PROFILE_TYPE_RETAIL_INTRADAY_MARGIN_1"
+	MarginProfileTypeUnspecified           MarginProfileType = "MARGIN_PROFILE_TYPE_UNSPECIFIED"
+)
+
+// GetFuturesBalanceSummary get the futures balances, buying power and margin of the user's CFM account.
+func (c *ApiClient) GetFuturesBalanceSummary() (FuturesBalanceSummary, error) {
+	return c.GetFuturesBalanceSummaryWithContext(context.Background())
+}
+
+// GetFuturesBalanceSummaryWithContext is like GetFuturesBalanceSummary but binds the request to the given context.
+func (c *ApiClient) GetFuturesBalanceSummaryWithContext(ctx context.Context) (FuturesBalanceSummary, error) {
+	u := c.makeV3Url("/brokerage/cfm/balance_summary")
+
+	var data GetFuturesBalanceSummaryData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.BalanceSummary, err
+	}
+	return data.BalanceSummary, nil
+}
+
+type GetFuturesBalanceSummaryData struct {
+	BalanceSummary FuturesBalanceSummary `json:"balance_summary"`
+}
+
+type FuturesBalanceSummary struct {
+	FuturesBuyingPower           Amount               `json:"futures_buying_power"`
+	TotalUsdBalance              Amount               `json:"total_usd_balance"`
+	CbiUsdBalance                Amount               `json:"cbi_usd_balance"`
+	CfmUsdBalance                Amount               `json:"cfm_usd_balance"`
+	TotalOpenOrdersHoldAmount    Amount               `json:"total_open_orders_hold_amount"`
+	UnrealizedPnl                Amount               `json:"unrealized_pnl"`
+	DailyRealizedPnl             Amount               `json:"daily_realized_pnl"`
+	InitialMargin                Amount               `json:"initial_margin"`
+	AvailableMargin              Amount               `json:"available_margin"`
+	LiquidationThreshold         Amount               `json:"liquidation_threshold"`
+	LiquidationBufferAmount      Amount               `json:"liquidation_buffer_amount"`
+	LiquidationBufferPercentage  Decimal              `json:"liquidation_buffer_percentage"`
+	IntradayMarginWindowMeasure  FuturesMarginMeasure `json:"intraday_margin_window_measure"`
+	OvernightMarginWindowMeasure FuturesMarginMeasure `json:"overnight_margin_window_measure"`
+}
+
+type FuturesMarginMeasure struct {
+	MarginWindowType            string  `json:"margin_window_type"`
+	MarginLevel                 string  `json:"margin_level"`
+	InitialMargin               Decimal `json:"initial_margin"`
+	MaintenanceMargin           Decimal `json:"maintenance_margin"`
+	LiquidationBufferPercentage Decimal `json:"liquidation_buffer_percentage"`
+	TotalHold                   Decimal `json:"total_hold"`
+	FuturesBuyingPower          Decimal `json:"futures_buying_power"`
+}
+
+// ListFuturesPositions get a list of the user's open futures positions.
+func (c *ApiClient) ListFuturesPositions() ([]FuturesPosition, error) {
+	return c.ListFuturesPositionsWithContext(context.Background())
+}
+
+// ListFuturesPositionsWithContext is like ListFuturesPositions but binds the request to the given context.
+func (c *ApiClient) ListFuturesPositionsWithContext(ctx context.Context) ([]FuturesPosition, error) {
+	u := c.makeV3Url("/brokerage/cfm/positions")
+
+	var data ListFuturesPositionsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return nil, err
+	}
+	return data.Positions, nil
+}
+
+type ListFuturesPositionsData struct {
+	Positions []FuturesPosition `json:"positions"`
+}
+
+// GetFuturesPosition get the user's open futures position for the given product id, e.g. BIT-28JUN24-CDE.
+func (c *ApiClient) GetFuturesPosition(productId string) (FuturesPosition, error) {
+	return c.GetFuturesPositionWithContext(context.Background(), productId)
+}
+
+// GetFuturesPositionWithContext is like GetFuturesPosition but binds the request to the given context.
+func (c *ApiClient) GetFuturesPositionWithContext(ctx context.Context, productId string) (FuturesPosition, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/cfm/positions/%s", productId))
+
+	var data GetFuturesPositionData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Position, err
+	}
+	return data.Position, nil
+}
+
+type GetFuturesPositionData struct {
+	Position FuturesPosition `json:"position"`
+}
+
+type FuturesPosition struct {
+	ProductId         string              `json:"product_id"`
+	ExpirationTime    time.Time           `json:"expiration_time"`
+	Side              FuturesPositionSide `json:"side"`
+	NumberOfContracts Decimal             `json:"number_of_contracts"`
+	CurrentPrice      Decimal             `json:"current_price"`
+	AvgEntryPrice     Decimal             `json:"avg_entry_price"`
+	UnrealizedPnl     Decimal             `json:"unrealized_pnl"`
+	DailyRealizedPnl  Decimal             `json:"daily_realized_pnl"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase may send an empty expiration time.
+func (f *FuturesPosition) UnmarshalJSON(data []byte) error {
+	type alias FuturesPosition
+	aux := struct {
+		ExpirationTime flexTime `json:"expiration_time"`
+		*alias
+	}{alias: (*alias)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	f.ExpirationTime = time.Time(aux.ExpirationTime)
+	return nil
+}
+
+// ScheduleFuturesSweep schedule a sweep of usdAmount from the futures account to the USD spot wallet. Sweeps are processed daily at 6 PM ET. An empty amount sweeps all available funds.
+func (c *ApiClient) ScheduleFuturesSweep(usdAmount Decimal) (bool, error) {
+	return c.ScheduleFuturesSweepWithContext(context.Background(), usdAmount)
+}
+
+// ScheduleFuturesSweepWithContext is like ScheduleFuturesSweep but binds the request to the given context.
+func (c *ApiClient) ScheduleFuturesSweepWithContext(ctx context.Context, usdAmount Decimal) (bool, error) {
+	u := c.makeV3Url("/brokerage/cfm/sweeps/schedule")
+
+	var data FuturesSweepResultData
+	body, err := json.Marshal(struct {
+		UsdAmount Decimal `json:"usd_amount,omitempty"`
+	}{usdAmount})
+	if err != nil {
+		return false, err
+	}
+
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return false, err
+	}
+	return data.Success, nil
+}
+
+type FuturesSweepResultData struct {
+	Success bool `json:"success"`
+}
+
+// ListFuturesSweeps get a list of the pending and processing sweeps of the futures account.
+func (c *ApiClient) ListFuturesSweeps() ([]FuturesSweep, error) {
+	return c.ListFuturesSweepsWithContext(context.Background())
+}
+
+// ListFuturesSweepsWithContext is like ListFuturesSweeps but binds the request to the given context.
+func (c *ApiClient) ListFuturesSweepsWithContext(ctx context.Context) ([]FuturesSweep, error) {
+	u := c.makeV3Url("/brokerage/cfm/sweeps")
+
+	var data ListFuturesSweepsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return nil, err
+	}
+	return data.Sweeps, nil
+}
+
+type ListFuturesSweepsData struct {
+	Sweeps []FuturesSweep `json:"sweeps"`
+}
+
+type FuturesSweep struct {
+	Id              string             `json:"id"`
+	RequestedAmount Amount             `json:"requested_amount"`
+	ShouldSweepAll  bool               `json:"should_sweep_all"`
+	Status          FuturesSweepStatus `json:"status"`
+	ScheduledTime   time.Time          `json:"scheduled_time"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because the scheduled time may be empty.
+func (f *FuturesSweep) UnmarshalJSON(data []byte) error {
+	type alias FuturesSweep
+	aux := struct {
+		ScheduledTime flexTime `json:"scheduled_time"`
+		*alias
+	}{alias: (*alias)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	f.ScheduledTime = time.Time(aux.ScheduledTime)
+	return nil
+}
+
+// CancelPendingFuturesSweep cancel the pending sweep of the futures account. A sweep that is already processing cannot be cancelled.
+func (c *ApiClient) CancelPendingFuturesSweep() (bool, error) {
+	return c.CancelPendingFuturesSweepWithContext(context.Background())
+}
+
+// CancelPendingFuturesSweepWithContext is like CancelPendingFuturesSweep but binds the request to the given context.
+func (c *ApiClient) CancelPendingFuturesSweepWithContext(ctx context.Context) (bool, error) {
+	u := c.makeV3Url("/brokerage/cfm/sweeps")
+
+	var data FuturesSweepResultData
+	if err := c.delete(ctx, u, &data); err != nil {
+		return false, err
+	}
+	return data.Success, nil
+}
+
+// GetIntradayMarginSetting get whether the futures account uses intraday or standard margin.
+func (c *ApiClient) GetIntradayMarginSetting() (IntradayMarginSetting, error) {
+	return c.GetIntradayMarginSettingWithContext(context.Background())
+}
+
+// GetIntradayMarginSettingWithContext is like GetIntradayMarginSetting but binds the request to the given context.
+func (c *ApiClient) GetIntradayMarginSettingWithContext(ctx context.Context) (IntradayMarginSetting, error) {
+	u := c.makeV3Url("/brokerage/cfm/intraday/margin_setting")
+
+	var data IntradayMarginSettingData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Setting, err
+	}
+	return data.Setting, nil
+}
+
+// SetIntradayMarginSetting opt the futures account in or out of intraday margin.
+func (c *ApiClient) SetIntradayMarginSetting(setting IntradayMarginSetting) error {
+	return c.SetIntradayMarginSettingWithContext(context.Background(), setting)
+}
+
+// SetIntradayMarginSettingWithContext is like SetIntradayMarginSetting but binds the request to the given context.
+func (c *ApiClient) SetIntradayMarginSettingWithContext(ctx context.Context, setting IntradayMarginSetting) error {
+	u := c.makeV3Url("/brokerage/cfm/intraday/margin_setting")
+
+	// setting the same value twice has no further effect
+	ctx = withRetrySafe(ctx)
+
+	body, err := json.Marshal(IntradayMarginSettingData{Setting: setting})
+	if err != nil {
+		return err
+	}
+	return c.post(ctx, u, body, nil)
+}
+
+type IntradayMarginSettingData struct {
+	Setting IntradayMarginSetting `json:"setting"`
+}
+
+// GetCurrentMarginWindow get the margin window that currently applies to the futures account. An empty margin profile type uses MarginProfileTypeRetailRegular.
+func (c *ApiClient) GetCurrentMarginWindow(profileType MarginProfileType) (CurrentMarginWindowData, error) {
+	return c.GetCurrentMarginWindowWithContext(context.Background(), profileType)
+}
+
+// GetCurrentMarginWindowWithContext is like GetCurrentMarginWindow but binds the request to the given context.
+func (c *ApiClient) GetCurrentMarginWindowWithContext(ctx context.Context, profileType MarginProfileType) (CurrentMarginWindowData, error) {
+	if profileType == "" {
+		profileType = MarginProfileTypeRetailRegular
+	}
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/cfm/intraday/current_margin_window?margin_profile_type=%s", profileType))
+
+	var data CurrentMarginWindowData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type CurrentMarginWindowData struct {
+	MarginWindow                                CurrentMarginWindow `json:"margin_window"`
+	IsIntradayMarginKillswitchEnabled           bool                `json:"is_intraday_margin_killswitch_enabled"`
+	IsIntradayMarginEnrollmentKillswitchEnabled bool                `json:"is_intraday_margin_enrollment_killswitch_enabled"`
+}
+
+type CurrentMarginWindow struct {
+	MarginWindowType string    `json:"margin_window_type"`
+	EndTime          time.Time `json:"end_time"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because the end time may be empty.
+func (c *CurrentMarginWindow) UnmarshalJSON(data []byte) error {
+	type alias CurrentMarginWindow
+	aux := struct {
+		EndTime flexTime `json:"end_time"`
+		*alias
+	}{alias: (*alias)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.EndTime = time.Time(aux.EndTime)
+	return nil
+}
@@ -0,0 +1,130 @@
+package coinbasev3
+
+import (
+	"github.com/jarcoal/httpmock"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestApiClient_GetFuturesBalanceSummary(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/cfm/balance_summary",
+		jsonResponder(`{"balance_summary":{"futures_buying_power":{"value":"1000","currency":"USD"},"total_usd_balance":{"value":"1500.25","currency":"USD"},"unrealized_pnl":{"value":"-12.5","currency":"USD"},"initial_margin":{"value":"95.6","currency":"USD"},"available_margin":{"value":"904.4","currency":"USD"},"liquidation_buffer_percentage":"1000","intraday_margin_window_measure":{"margin_window_type":"FCM_MARGIN_WINDOW_TYPE_INTRADAY","margin_level":"MARGIN_LEVEL_TYPE_BASE","initial_margin":"95.6","maintenance_margin":"70.2","futures_buying_power":"1000"}}}`))
+
+	data, err := api.GetFuturesBalanceSummary()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if data.FuturesBuyingPower.Value != "1000" || data.TotalUsdBalance.Value != "1500.25" || data.UnrealizedPnl.Value.Sign() >= 0 {
+		t.Errorf("Unexpected balance summary %+v", data)
+	}
+	if data.IntradayMarginWindowMeasure.MaintenanceMargin != "70.2" {
+		t.Errorf("Expected a maintenance margin of 70.2, got %s", data.IntradayMarginWindowMeasure.MaintenanceMargin)
+	}
+}
+
+func TestApiClient_FuturesPositions(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	position := `{"product_id":"BIT-28JUN24-CDE","expiration_time":"2024-06-28T15:00:00Z","side":"LONG","number_of_contracts":"2","current_price":"65000","avg_entry_price":"64000","unrealized_pnl":"20","daily_realized_pnl":"0"}`
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/cfm/positions",
+		jsonResponder(`{"positions":[`+position+`]}`))
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/cfm/positions/BIT-28JUN24-CDE",
+		jsonResponder(`{"position":`+position+`}`))
+
+	positions, err := api.ListFuturesPositions()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(positions) != 1 || positions[0].Side != FuturesPositionSideLong {
+		t.Fatalf("Unexpected positions %+v", positions)
+	}
+
+	p, err := api.GetFuturesPosition("BIT-28JUN24-CDE")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if p.NumberOfContracts != "2" || p.ExpirationTime.Year() != 2024 || p.AvgEntryPrice != "64000" {
+		t.Errorf("Unexpected position %+v", p)
+	}
+}
+
+func TestApiClient_FuturesSweeps(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var scheduleBody string
+	cancelled := false
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/cfm/sweeps/schedule", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		scheduleBody = string(b)
+		return jsonResponder(`{"success":true}`)(request)
+	})
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/cfm/sweeps",
+		jsonResponder(`{"sweeps":[{"id":"s-1","requested_amount":{"value":"250","currency":"USD"},"should_sweep_all":false,"status":"PENDING","scheduled_time":"2024-06-01T22:00:00Z"}]}`))
+	httpmock.RegisterResponder("DELETE", "https://api.coinbase.com/api/v3/brokerage/cfm/sweeps", func(request *http.Request) (*http.Response, error) {
+		cancelled = true
+		return jsonResponder(`{"success":true}`)(request)
+	})
+
+	ok, err := api.ScheduleFuturesSweep("250")
+	if err != nil || !ok {
+		t.Fatalf("Expected the sweep to be scheduled, got %v", err)
+	}
+	if scheduleBody != `{"usd_amount":"250"}` {
+		t.Errorf("Unexpected request body %s", scheduleBody)
+	}
+
+	sweeps, err := api.ListFuturesSweeps()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(sweeps) != 1 || sweeps[0].Status != FuturesSweepStatusPending || sweeps[0].RequestedAmount.Value != "250" {
+		t.Fatalf("Unexpected sweeps %+v", sweeps)
+	}
+
+	ok, err = api.CancelPendingFuturesSweep()
+	if err != nil || !ok || !cancelled {
+		t.Errorf("Expected the sweep to be cancelled, got %v", err)
+	}
+}
+
+func TestApiClient_IntradayMargin(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var settingBody string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/cfm/intraday/margin_setting",
+		jsonResponder(`{"setting":"INTRADAY_MARGIN_SETTING_STANDARD"}`))
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/cfm/intraday/margin_setting", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		settingBody = string(b)
+		return jsonResponder(`{}`)(request)
+	})
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/cfm/intraday/current_margin_window", "margin_profile_type=MARGIN_PROFILE_TYPE_RETAIL_REGULAR",
+		jsonResponder(`{"margin_window":{"margin_window_type":"FCM_MARGIN_WINDOW_TYPE_INTRADAY","end_time":"2024-06-01T20:00:00Z"},"is_intraday_margin_killswitch_enabled":false,"is_intraday_margin_enrollment_killswitch_enabled":false}`))
+
+	setting, err := api.GetIntradayMarginSetting()
+	if err != nil || setting != IntradayMarginSettingStandard {
+		t.Fatalf("Unexpected setting %s: %v", setting, err)
+	}
+
+	if err := api.SetIntradayMarginSetting(IntradayMarginSettingIntraday); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if settingBody != `{"setting":"INTRADAY_MARGIN_SETTING_INTRADAY"}` {
+		t.Errorf("Unexpected request body %s", settingBody)
+	}
+
+	window, err := api.GetCurrentMarginWindow("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if window.MarginWindow.MarginWindowType != "FCM_MARGIN_WINDOW_TYPE_INTRADAY" || window.MarginWindow.EndTime.Hour() != 20 {
+		t.Errorf("Unexpected margin window %+v", window)
+	}
+}
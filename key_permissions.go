@@ -0,0 +1,112 @@
+package coinbasev3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrApiKeyPermissions = fmt.Errorf("api key permissions do not match")
+)
+
+type ApiKeyPermission string
+
+const (
+	ApiKeyPermissionView     ApiKeyPermission = "can_view"
+	ApiKeyPermissionTrade    ApiKeyPermission = "can_trade"
+	ApiKeyPermissionTransfer ApiKeyPermission = "can_transfer"
+)
+
+type ApiKeyPermissions struct {
+	CanView       bool          `json:"can_view"`
+	CanTrade      bool          `json:"can_trade"`
+	CanTransfer   bool          `json:"can_transfer"`
+	PortfolioUuid string        `json:"portfolio_uuid"`
+	PortfolioType PortfolioType `json:"portfolio_type"`
+}
+
+// Has reports whether the key has the given permission.
+func (p ApiKeyPermissions) Has(permission ApiKeyPermission) bool {
+	switch permission {
+	case ApiKeyPermissionView:
+		return p.CanView
+	case ApiKeyPermissionTrade:
+		return p.CanTrade
+	case ApiKeyPermissionTransfer:
+		return p.CanTransfer
+	}
+	return false
+}
+
+// GetApiKeyPermissions get the permissions of the API key the client is authenticated with, and the portfolio it is scoped to.
+func (c *ApiClient) GetApiKeyPermissions() (ApiKeyPermissions, error) {
+	return c.GetApiKeyPermissionsWithContext(context.Background())
+}
+
+// GetApiKeyPermissionsWithContext is like GetApiKeyPermissions but binds the request to the given context.
+func (c *ApiClient) GetApiKeyPermissionsWithContext(ctx context.Context) (ApiKeyPermissions, error) {
+	u := c.makeV3Url("/brokerage/key_permissions")
+
+	var data ApiKeyPermissions
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// ApiKeyPolicy describes the permissions an API key is expected to have.
+type ApiKeyPolicy struct {
+	// Require lists the permissions the key must have.
+	Require []ApiKeyPermission
+	// Forbid lists the permissions the key must not have, e.g. ApiKeyPermissionTransfer for a trading bot.
+	Forbid []ApiKeyPermission
+	// PortfolioUuid is the portfolio the key must be scoped to. Empty accepts any portfolio.
+	PortfolioUuid string
+}
+
+// NewApiClientWithPolicy creates a Coinbase API client like NewApiClientWithAuthenticator and asserts at startup that the API key satisfies the policy. The client is only returned when the assertion passes; a mismatch returns an error that matches ErrApiKeyPermissions.
+func NewApiClientWithPolicy(auth Authenticator, policy ApiKeyPolicy, clients ...HttpClient) (*ApiClient, error) {
+	ac := NewApiClientWithAuthenticator(auth, clients...)
+	if _, err := ac.AssertApiKeyPermissions(policy); err != nil {
+		return nil, err
+	}
+	return ac, nil
+}
+
+// AssertApiKeyPermissions fetches the permissions of the API key and checks them against the policy. NewApiClientWithPolicy runs it when the client is created. A mismatch returns an error that matches ErrApiKeyPermissions.
+func (c *ApiClient) AssertApiKeyPermissions(policy ApiKeyPolicy) (ApiKeyPermissions, error) {
+	return c.AssertApiKeyPermissionsWithContext(context.Background(), policy)
+}
+
+// AssertApiKeyPermissionsWithContext is like AssertApiKeyPermissions but binds the request to the given context.
+func (c *ApiClient) AssertApiKeyPermissionsWithContext(ctx context.Context, policy ApiKeyPolicy) (ApiKeyPermissions, error) {
+	perms, err := c.GetApiKeyPermissionsWithContext(ctx)
+	if err != nil {
+		return perms, err
+	}
+	return perms, policy.Check(perms)
+}
+
+// Check reports every way the permissions violate the policy in a single error, or nil if they satisfy it.
+func (policy ApiKeyPolicy) Check(perms ApiKeyPermissions) error {
+	var problems []string
+	for _, p := range policy.Require {
+		if !perms.Has(p) {
+			problems = append(problems, fmt.Sprintf("missing %s", p))
+		}
+	}
+	for _, p := range policy.Forbid {
+		if perms.Has(p) {
+			problems = append(problems, fmt.Sprintf("unexpected %s", p))
+		}
+	}
+	if policy.PortfolioUuid != "" && perms.PortfolioUuid != policy.PortfolioUuid {
+		problems = append(problems, fmt.Sprintf("scoped to portfolio %s instead of %s", perms.PortfolioUuid, policy.PortfolioUuid))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrApiKeyPermissions, strings.Join(problems, ", "))
+	}
+	return nil
+}
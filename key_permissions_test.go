@@ -0,0 +1,79 @@
+package coinbasev3
+
+import (
+	"errors"
+	"github.com/imroc/req/v3"
+	"github.com/jarcoal/httpmock"
+	"testing"
+)
+
+func TestApiClient_GetApiKeyPermissions(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/key_permissions",
+		jsonResponder(`{"can_view":true,"can_trade":true,"can_transfer":false,"portfolio_uuid":"p-1","portfolio_type":"DEFAULT"}`))
+
+	perms, err := api.GetApiKeyPermissions()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !perms.CanView || !perms.CanTrade || perms.CanTransfer || perms.PortfolioType != PortfolioTypeDefault {
+		t.Errorf("Unexpected permissions %+v", perms)
+	}
+
+	_, err = api.AssertApiKeyPermissions(ApiKeyPolicy{
+		Require:       []ApiKeyPermission{ApiKeyPermissionView, ApiKeyPermissionTrade},
+		Forbid:        []ApiKeyPermission{ApiKeyPermissionTransfer},
+		PortfolioUuid: "p-1",
+	})
+	if err != nil {
+		t.Errorf("Expected the policy to be satisfied, got %s", err)
+	}
+}
+
+func TestApiKeyPolicy_Check(t *testing.T) {
+	perms := ApiKeyPermissions{CanView: true, CanTrade: false, CanTransfer: true, PortfolioUuid: "p-1"}
+	policy := ApiKeyPolicy{
+		Require:       []ApiKeyPermission{ApiKeyPermissionTrade},
+		Forbid:        []ApiKeyPermission{ApiKeyPermissionTransfer},
+		PortfolioUuid: "p-2",
+	}
+
+	err := policy.Check(perms)
+	if !errors.Is(err, ErrApiKeyPermissions) {
+		t.Fatalf("Expected ErrApiKeyPermissions, got %v", err)
+	}
+	want := "api key permissions do not match: missing can_trade, unexpected can_transfer, scoped to portfolio p-1 instead of p-2"
+	if err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+
+	if err := (ApiKeyPolicy{}).Check(perms); err != nil {
+		t.Errorf("Expected an empty policy to accept any key, got %s", err)
+	}
+}
+
+func TestNewApiClientWithPolicy(t *testing.T) {
+	hc := &ReqClient{client: req.C()}
+	httpmock.ActivateNonDefault(hc.GetClient().GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/key_permissions",
+		jsonResponder(`{"can_view":true,"can_trade":true,"can_transfer":true,"portfolio_uuid":"p-1","portfolio_type":"DEFAULT"}`))
+
+	auth := NewHmacAuthenticator("api_key", "secret_key")
+	api, err := NewApiClientWithPolicy(auth, ApiKeyPolicy{Require: []ApiKeyPermission{ApiKeyPermissionTrade}}, hc)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if api == nil {
+		t.Fatal("Expected a client")
+	}
+
+	api, err = NewApiClientWithPolicy(auth, ApiKeyPolicy{Forbid: []ApiKeyPermission{ApiKeyPermissionTransfer}}, hc)
+	if !errors.Is(err, ErrApiKeyPermissions) {
+		t.Fatalf("Expected ErrApiKeyPermissions, got %v", err)
+	}
+	if api != nil {
+		t.Error("Expected no client when the policy is violated")
+	}
+}
@@ -0,0 +1,487 @@
+package coinbasev3
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidOrder = fmt.Errorf("invalid order")
+)
+
+// OrderBuilder builds a CreateOrderRequest with exactly one order configuration.
+//
+//	req, err := coinbasev3.LimitBuyGtc("BTC-USD", "0.001", "30000.00").PostOnly().BuildFor(product)
+//	if err != nil {
+//		panic(err)
+//	}
+//	order, err := client.CreateOrder(req)
+type OrderBuilder struct {
+	req CreateOrderRequest
+	err error
+}
+
+func newOrderBuilder(productId string, side OrderSide, cfg OrderConfiguration) *OrderBuilder {
+	return &OrderBuilder{
+		req: CreateOrderRequest{
+			ProductID:          productId,
+			Side:               side,
+			OrderConfiguration: cfg,
+		},
+	}
+}
+
+// MarketBuy builds a market order that spends quoteSize of the quote currency, e.g. 100 USD of BTC-USD.
+func MarketBuy(productId string, quoteSize Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		MarketMarketIoc: &MarketMarketIoc{QuoteSize: quoteSize},
+	})
+}
+
+// MarketSell builds a market order that sells baseSize of the base currency.
+func MarketSell(productId string, baseSize Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		MarketMarketIoc: &MarketMarketIoc{BaseSize: baseSize},
+	})
+}
+
+// LimitBuyGtc builds a good-till-cancelled limit buy order.
+func LimitBuyGtc(productId string, baseSize, limitPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		LimitLimitGtc: &LimitLimitGtc{BaseSize: baseSize, LimitPrice: limitPrice},
+	})
+}
+
+// LimitSellGtc builds a good-till-cancelled limit sell order.
+func LimitSellGtc(productId string, baseSize, limitPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		LimitLimitGtc: &LimitLimitGtc{BaseSize: baseSize, LimitPrice: limitPrice},
+	})
+}
+
+// LimitBuyGtd builds a limit buy order that expires at endTime.
+func LimitBuyGtd(productId string, baseSize, limitPrice Decimal, endTime time.Time) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		LimitLimitGtd: &LimitLimitGtd{BaseSize: baseSize, LimitPrice: limitPrice, EndTime: endTime},
+	})
+}
+
+// LimitSellGtd builds a limit sell order that expires at endTime.
+func LimitSellGtd(productId string, baseSize, limitPrice Decimal, endTime time.Time) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		LimitLimitGtd: &LimitLimitGtd{BaseSize: baseSize, LimitPrice: limitPrice, EndTime: endTime},
+	})
+}
+
+// StopLimitBuyGtc builds a good-till-cancelled stop-limit buy order that triggers when the price rises to stopPrice.
+func StopLimitBuyGtc(productId string, baseSize, limitPrice, stopPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		StopLimitStopLimitGtc: &StopLimitStopLimitGtc{BaseSize: baseSize, LimitPrice: limitPrice, StopPrice: stopPrice, StopDirection: StopDirectionStopUp},
+	})
+}
+
+// StopLimitSellGtc builds a good-till-cancelled stop-limit sell order that triggers when the price falls to stopPrice.
+func StopLimitSellGtc(productId string, baseSize, limitPrice, stopPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		StopLimitStopLimitGtc: &StopLimitStopLimitGtc{BaseSize: baseSize, LimitPrice: limitPrice, StopPrice: stopPrice, StopDirection: StopDirectionStopDown},
+	})
+}
+
+// StopLimitBuyGtd builds a stop-limit buy order that triggers when the price rises to stopPrice and expires at endTime.
+func StopLimitBuyGtd(productId string, baseSize, limitPrice, stopPrice Decimal, endTime time.Time) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		StopLimitStopLimitGtd: &StopLimitStopLimitGtd{BaseSize: baseSize, LimitPrice: limitPrice, StopPrice: stopPrice, EndTime: endTime, StopDirection: StopDirectionStopUp},
+	})
+}
+
+// StopLimitSellGtd builds a stop-limit sell order that triggers when the price falls to stopPrice and expires at endTime.
+func StopLimitSellGtd(productId string, baseSize, limitPrice, stopPrice Decimal, endTime time.Time) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		StopLimitStopLimitGtd: &StopLimitStopLimitGtd{BaseSize: baseSize, LimitPrice: limitPrice, StopPrice: stopPrice, EndTime: endTime, StopDirection: StopDirectionStopDown},
+	})
+}
+
+// SorLimitBuyIoc builds an immediate-or-cancel limit buy order routed by the smart order router.
+func SorLimitBuyIoc(productId string, baseSize, limitPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		SorLimitIoc: &SorLimitIoc{BaseSize: baseSize, LimitPrice: limitPrice},
+	})
+}
+
+// SorLimitSellIoc builds an immediate-or-cancel limit sell order routed by the smart order router.
+func SorLimitSellIoc(productId string, baseSize, limitPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		SorLimitIoc: &SorLimitIoc{BaseSize: baseSize, LimitPrice: limitPrice},
+	})
+}
+
+// LimitBuyFok builds a fill-or-kill limit buy order.
+func LimitBuyFok(productId string, baseSize, limitPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		LimitLimitFok: &LimitLimitFok{BaseSize: baseSize, LimitPrice: limitPrice},
+	})
+}
+
+// LimitSellFok builds a fill-or-kill limit sell order.
+func LimitSellFok(productId string, baseSize, limitPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		LimitLimitFok: &LimitLimitFok{BaseSize: baseSize, LimitPrice: limitPrice},
+	})
+}
+
+// TriggerBracketBuyGtc builds a good-till-cancelled bracket buy order with a limit price and a stop trigger price.
+func TriggerBracketBuyGtc(productId string, baseSize, limitPrice, stopTriggerPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		TriggerBracketGtc: &TriggerBracketGtc{BaseSize: baseSize, LimitPrice: limitPrice, StopTriggerPrice: stopTriggerPrice},
+	})
+}
+
+// TriggerBracketSellGtc builds a good-till-cancelled bracket sell order that takes profit at limitPrice and stops the loss at stopTriggerPrice.
+func TriggerBracketSellGtc(productId string, baseSize, limitPrice, stopTriggerPrice Decimal) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		TriggerBracketGtc: &TriggerBracketGtc{BaseSize: baseSize, LimitPrice: limitPrice, StopTriggerPrice: stopTriggerPrice},
+	})
+}
+
+// TriggerBracketBuyGtd builds a bracket buy order that expires at endTime.
+func TriggerBracketBuyGtd(productId string, baseSize, limitPrice, stopTriggerPrice Decimal, endTime time.Time) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		TriggerBracketGtd: &TriggerBracketGtd{BaseSize: baseSize, LimitPrice: limitPrice, StopTriggerPrice: stopTriggerPrice, EndTime: endTime},
+	})
+}
+
+// TriggerBracketSellGtd builds a bracket sell order that expires at endTime.
+func TriggerBracketSellGtd(productId string, baseSize, limitPrice, stopTriggerPrice Decimal, endTime time.Time) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		TriggerBracketGtd: &TriggerBracketGtd{BaseSize: baseSize, LimitPrice: limitPrice, StopTriggerPrice: stopTriggerPrice, EndTime: endTime},
+	})
+}
+
+// TwapLimitBuyGtd builds a TWAP buy order that buys baseSize evenly between startTime and endTime at no more than limitPrice.
+func TwapLimitBuyGtd(productId string, baseSize, limitPrice Decimal, startTime, endTime time.Time) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideBuy, OrderConfiguration{
+		TwapLimitGtd: &TwapLimitGtd{BaseSize: baseSize, LimitPrice: limitPrice, StartTime: startTime, EndTime: endTime},
+	})
+}
+
+// TwapLimitSellGtd builds a TWAP sell order that sells baseSize evenly between startTime and endTime at no less than limitPrice.
+func TwapLimitSellGtd(productId string, baseSize, limitPrice Decimal, startTime, endTime time.Time) *OrderBuilder {
+	return newOrderBuilder(productId, OrderSideSell, OrderConfiguration{
+		TwapLimitGtd: &TwapLimitGtd{BaseSize: baseSize, LimitPrice: limitPrice, StartTime: startTime, EndTime: endTime},
+	})
+}
+
+// ClientOrderId sets the client order id Coinbase uses to de-duplicate orders. Build generates one when it is not set.
+func (b *OrderBuilder) ClientOrderId(id string) *OrderBuilder {
+	b.req.ClientOrderID = id
+	return b
+}
+
+// Portfolio places the order in the portfolio with the given uuid instead of the default portfolio.
+func (b *OrderBuilder) Portfolio(uuid string) *OrderBuilder {
+	b.req.RetailPortfolioId = uuid
+	return b
+}
+
+// PostOnly makes a limit order maker only: it is rejected instead of filled immediately. Only limit orders support it.
+func (b *OrderBuilder) PostOnly() *OrderBuilder {
+	cfg := b.req.OrderConfiguration
+	switch {
+	case cfg.LimitLimitGtc != nil:
+		cfg.LimitLimitGtc.PostOnly = true
+	case cfg.LimitLimitGtd != nil:
+		cfg.LimitLimitGtd.PostOnly = true
+	default:
+		b.err = fmt.Errorf("%w: post only is only supported by limit orders", ErrInvalidOrder)
+	}
+	return b
+}
+
+// Buckets sets the number of buckets a TWAP order is split into. Coinbase picks the number when it is not set.
+func (b *OrderBuilder) Buckets(n int) *OrderBuilder {
+	cfg := b.req.OrderConfiguration
+	if cfg.TwapLimitGtd == nil {
+		b.err = fmt.Errorf("%w: buckets are only supported by twap orders", ErrInvalidOrder)
+		return b
+	}
+	if n <= 0 {
+		b.err = fmt.Errorf("%w: number_buckets must be positive", ErrInvalidOrder)
+		return b
+	}
+	cfg.TwapLimitGtd.NumberBuckets = strconv.Itoa(n)
+	return b
+}
+
+// StopDirection overrides the stop direction of a stop-limit order. By default buy orders stop up and sell orders stop down.
+func (b *OrderBuilder) StopDirection(d StopDirection) *OrderBuilder {
+	cfg := b.req.OrderConfiguration
+	switch {
+	case cfg.StopLimitStopLimitGtc != nil:
+		cfg.StopLimitStopLimitGtc.StopDirection = d
+	case cfg.StopLimitStopLimitGtd != nil:
+		cfg.StopLimitStopLimitGtd.StopDirection = d
+	default:
+		b.err = fmt.Errorf("%w: stop direction is only supported by stop-limit orders", ErrInvalidOrder)
+	}
+	return b
+}
+
+// Build returns the request. A random client order id is generated if none was set.
+func (b *OrderBuilder) Build() (CreateOrderRequest, error) {
+	if b.err != nil {
+		return CreateOrderRequest{}, b.err
+	}
+	if err := b.req.OrderConfiguration.Validate(); err != nil {
+		return CreateOrderRequest{}, err
+	}
+
+	req := b.req
+	if req.ClientOrderID == "" {
+		id, err := newClientOrderId()
+		if err != nil {
+			return CreateOrderRequest{}, err
+		}
+		req.ClientOrderID = id
+	}
+	return req, nil
+}
+
+// BuildFor is like Build but also validates the request against the product, see CreateOrderRequest.Validate.
+func (b *OrderBuilder) BuildFor(p Product) (CreateOrderRequest, error) {
+	req, err := b.Build()
+	if err != nil {
+		return req, err
+	}
+	if err = req.Validate(p); err != nil {
+		return CreateOrderRequest{}, err
+	}
+	return req, nil
+}
+
+// newClientOrderId returns a random version 4 UUID.
+func newClientOrderId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// Validate checks that exactly one configuration is set and that its sizes and prices are positive.
+func (cfg OrderConfiguration) Validate() error {
+	var set []string
+	var err error
+
+	if c := cfg.MarketMarketIoc; c != nil {
+		set = append(set, "market_market_ioc")
+		err = checkSizes(c.QuoteSize, c.BaseSize)
+	}
+	if c := cfg.LimitLimitGtc; c != nil {
+		set = append(set, "limit_limit_gtc")
+		err = firstErr(err, checkPositive("base_size", c.BaseSize), checkPositive("limit_price", c.LimitPrice))
+	}
+	if c := cfg.LimitLimitGtd; c != nil {
+		set = append(set, "limit_limit_gtd")
+		err = firstErr(err, checkPositive("base_size", c.BaseSize), checkPositive("limit_price", c.LimitPrice), checkEndTime(c.EndTime))
+	}
+	if c := cfg.StopLimitStopLimitGtc; c != nil {
+		set = append(set, "stop_limit_stop_limit_gtc")
+		err = firstErr(err, checkPositive("base_size", c.BaseSize), checkPositive("limit_price", c.LimitPrice), checkPositive("stop_price", c.StopPrice))
+	}
+	if c := cfg.StopLimitStopLimitGtd; c != nil {
+		set = append(set, "stop_limit_stop_limit_gtd")
+		err = firstErr(err, checkPositive("base_size", c.BaseSize), checkPositive("limit_price", c.LimitPrice), checkPositive("stop_price", c.StopPrice), checkEndTime(c.EndTime))
+	}
+
+	if c := cfg.SorLimitIoc; c != nil {
+		set = append(set, "sor_limit_ioc")
+		err = firstErr(err, checkSizes(c.QuoteSize, c.BaseSize), checkPositive("limit_price", c.LimitPrice))
+	}
+	if c := cfg.LimitLimitFok; c != nil {
+		set = append(set, "limit_limit_fok")
+		err = firstErr(err, checkSizes(c.QuoteSize, c.BaseSize), checkPositive("limit_price", c.LimitPrice))
+	}
+	if c := cfg.TriggerBracketGtc; c != nil {
+		set = append(set, "trigger_bracket_gtc")
+		err = firstErr(err, checkPositive("base_size", c.BaseSize), checkPositive("limit_price", c.LimitPrice), checkPositive("stop_trigger_price", c.StopTriggerPrice))
+	}
+	if c := cfg.TriggerBracketGtd; c != nil {
+		set = append(set, "trigger_bracket_gtd")
+		err = firstErr(err, checkPositive("base_size", c.BaseSize), checkPositive("limit_price", c.LimitPrice), checkPositive("stop_trigger_price", c.StopTriggerPrice), checkEndTime(c.EndTime))
+	}
+	if c := cfg.TwapLimitGtd; c != nil {
+		set = append(set, "twap_limit_gtd")
+		err = firstErr(err, checkSizes(c.QuoteSize, c.BaseSize), checkPositive("limit_price", c.LimitPrice), checkEndTime(c.EndTime))
+		if err == nil && !c.EndTime.After(c.StartTime) {
+			err = fmt.Errorf("%w: end_time must be after start_time", ErrInvalidOrder)
+		}
+	}
+
+	switch {
+	case len(set) == 0:
+		return fmt.Errorf("%w: no order configuration set", ErrInvalidOrder)
+	case len(set) > 1:
+		return fmt.Errorf("%w: only one order configuration can be set, got %s", ErrInvalidOrder, strings.Join(set, ", "))
+	}
+	return err
+}
+
+// Validate checks the request against the product before it is sent: the product must be tradable, the order type allowed by the product's cancel_only, limit_only and post_only flags, and every size and price a multiple of the product's increments within its min and max sizes.
+func (req CreateOrderRequest) Validate(p Product) error {
+	cfg := req.OrderConfiguration
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if p.ProductId != "" && req.ProductID != p.ProductId {
+		return fmt.Errorf("%w: order is for %s but the product is %s", ErrInvalidOrder, req.ProductID, p.ProductId)
+	}
+	if req.Side != OrderSideBuy && req.Side != OrderSideSell {
+		return fmt.Errorf("%w: side must be %s or %s", ErrInvalidOrder, OrderSideBuy, OrderSideSell)
+	}
+	if p.TradingDisabled || p.IsDisabled || (p.Status != "" && !strings.EqualFold(p.Status, "online")) {
+		return fmt.Errorf("%w: %s is not tradable", ErrInvalidOrder, p.ProductId)
+	}
+	if p.CancelOnly {
+		return fmt.Errorf("%w: %s is in cancel only mode", ErrInvalidOrder, p.ProductId)
+	}
+
+	postOnly := (cfg.LimitLimitGtc != nil && cfg.LimitLimitGtc.PostOnly) || (cfg.LimitLimitGtd != nil && cfg.LimitLimitGtd.PostOnly)
+	if p.LimitOnly && cfg.MarketMarketIoc != nil {
+		return fmt.Errorf("%w: %s is in limit only mode", ErrInvalidOrder, p.ProductId)
+	}
+	if p.PostOnly && !postOnly {
+		return fmt.Errorf("%w: %s is in post only mode", ErrInvalidOrder, p.ProductId)
+	}
+
+	var quoteSize, baseSize, limitPrice, stopPrice Decimal
+	switch {
+	case cfg.MarketMarketIoc != nil:
+		quoteSize, baseSize = cfg.MarketMarketIoc.QuoteSize, cfg.MarketMarketIoc.BaseSize
+	case cfg.LimitLimitGtc != nil:
+		baseSize, limitPrice = cfg.LimitLimitGtc.BaseSize, cfg.LimitLimitGtc.LimitPrice
+	case cfg.LimitLimitGtd != nil:
+		baseSize, limitPrice = cfg.LimitLimitGtd.BaseSize, cfg.LimitLimitGtd.LimitPrice
+	case cfg.StopLimitStopLimitGtc != nil:
+		baseSize, limitPrice, stopPrice = cfg.StopLimitStopLimitGtc.BaseSize, cfg.StopLimitStopLimitGtc.LimitPrice, cfg.StopLimitStopLimitGtc.StopPrice
+	case cfg.StopLimitStopLimitGtd != nil:
+		baseSize, limitPrice, stopPrice = cfg.StopLimitStopLimitGtd.BaseSize, cfg.StopLimitStopLimitGtd.LimitPrice, cfg.StopLimitStopLimitGtd.StopPrice
+	case cfg.SorLimitIoc != nil:
+		quoteSize, baseSize, limitPrice = cfg.SorLimitIoc.QuoteSize, cfg.SorLimitIoc.BaseSize, cfg.SorLimitIoc.LimitPrice
+	case cfg.LimitLimitFok != nil:
+		quoteSize, baseSize, limitPrice = cfg.LimitLimitFok.QuoteSize, cfg.LimitLimitFok.BaseSize, cfg.LimitLimitFok.LimitPrice
+	case cfg.TriggerBracketGtc != nil:
+		baseSize, limitPrice, stopPrice = cfg.TriggerBracketGtc.BaseSize, cfg.TriggerBracketGtc.LimitPrice, cfg.TriggerBracketGtc.StopTriggerPrice
+	case cfg.TriggerBracketGtd != nil:
+		baseSize, limitPrice, stopPrice = cfg.TriggerBracketGtd.BaseSize, cfg.TriggerBracketGtd.LimitPrice, cfg.TriggerBracketGtd.StopTriggerPrice
+	case cfg.TwapLimitGtd != nil:
+		quoteSize, baseSize, limitPrice = cfg.TwapLimitGtd.QuoteSize, cfg.TwapLimitGtd.BaseSize, cfg.TwapLimitGtd.LimitPrice
+	}
+
+	var err error
+	if quoteSize != "" {
+		err = p.checkQuoteSize(quoteSize)
+	} else {
+		err = p.checkBaseSize(baseSize)
+	}
+	if limitPrice != "" {
+		err = firstErr(err, p.checkPrice("limit_price", limitPrice))
+	}
+	if stopPrice != "" {
+		err = firstErr(err, p.checkPrice("stop_price", stopPrice))
+	}
+	if err != nil || quoteSize != "" || limitPrice == "" {
+		return err
+	}
+
+	// the notional value of the order has to meet the quote minimum as well
+	if p.QuoteMinSize.IsValid() && baseSize.Mul(limitPrice).LessThan(p.QuoteMinSize) {
+		return fmt.Errorf("%w: order value %s is below the minimum of %s", ErrInvalidOrder, baseSize.Mul(limitPrice), p.QuoteMinSize)
+	}
+	return nil
+}
+
+func (p Product) checkBaseSize(size Decimal) error {
+	return firstErr(
+		checkIncrement("base_size", size, p.BaseIncrement),
+		checkRange("base_size", size, p.BaseMinSize, p.BaseMaxSize),
+	)
+}
+
+func (p Product) checkQuoteSize(size Decimal) error {
+	return firstErr(
+		checkIncrement("quote_size", size, p.QuoteIncrement),
+		checkRange("quote_size", size, p.QuoteMinSize, p.QuoteMaxSize),
+	)
+}
+
+func (p Product) checkPrice(name string, price Decimal) error {
+	increment := p.PriceIncrement
+	if !increment.IsValid() || increment.IsZero() {
+		increment = p.QuoteIncrement
+	}
+	return checkIncrement(name, price, increment)
+}
+
+// checkSizes checks that exactly one of the quote and base size is set and positive.
+func checkSizes(quoteSize, baseSize Decimal) error {
+	switch {
+	case quoteSize != "" && baseSize != "":
+		return fmt.Errorf("%w: either quote_size or base_size must be set, not both", ErrInvalidOrder)
+	case quoteSize != "":
+		return checkPositive("quote_size", quoteSize)
+	default:
+		return checkPositive("base_size", baseSize)
+	}
+}
+
+func checkPositive(name string, v Decimal) error {
+	if !v.IsValid() || v.Sign() <= 0 {
+		return fmt.Errorf("%w: %s must be a positive number, got %q", ErrInvalidOrder, name, v)
+	}
+	return nil
+}
+
+func checkEndTime(t time.Time) error {
+	if !t.After(time.Now()) {
+		return fmt.Errorf("%w: end_time must be in the future", ErrInvalidOrder)
+	}
+	return nil
+}
+
+// checkIncrement checks that v is a multiple of increment. An unknown increment is not checked.
+func checkIncrement(name string, v, increment Decimal) error {
+	if !increment.IsValid() || increment.IsZero() {
+		return nil
+	}
+	if !v.RoundToIncrement(increment).Equal(v) {
+		return fmt.Errorf("%w: %s %s is not a multiple of %s", ErrInvalidOrder, name, v, increment)
+	}
+	return nil
+}
+
+// checkRange checks that min <= v <= max. An unknown bound is not checked.
+func checkRange(name string, v, min, max Decimal) error {
+	if min.IsValid() && v.LessThan(min) {
+		return fmt.Errorf("%w: %s %s is below the minimum of %s", ErrInvalidOrder, name, v, min)
+	}
+	if max.IsValid() && !max.IsZero() && v.GreaterThan(max) {
+		return fmt.Errorf("%w: %s %s is above the maximum of %s", ErrInvalidOrder, name, v, max)
+	}
+	return nil
+}
+
+// firstErr returns the first non-nil error.
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
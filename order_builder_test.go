@@ -0,0 +1,191 @@
+package coinbasev3
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testProduct() Product {
+	return Product{
+		ProductId:      "BTC-USD",
+		Status:         "online",
+		BaseIncrement:  "0.00000001",
+		QuoteIncrement: "0.01",
+		BaseMinSize:    "0.00001",
+		BaseMaxSize:    "3400",
+		QuoteMinSize:   "1",
+		QuoteMaxSize:   "150000000",
+	}
+}
+
+func TestOrderBuilder_JSON(t *testing.T) {
+	req, err := MarketBuy("BTC-USD", "10.00").ClientOrderId("0000").Build()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	b, err := req.ToJson()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	want := `{"client_order_id":"0000","product_id":"BTC-USD","side":"BUY","order_configuration":{"market_market_ioc":{"quote_size":"10.00"}}}`
+	if string(b) != want {
+		t.Errorf("Expected %s, got %s", want, b)
+	}
+
+	req, err = StopLimitSellGtc("BTC-USD", "0.5", "29000", "29500").Build()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(req.ClientOrderID) != 36 {
+		t.Errorf("Expected a generated client order id, got %s", req.ClientOrderID)
+	}
+	if req.OrderConfiguration.StopLimitStopLimitGtc.StopDirection != StopDirectionStopDown {
+		t.Errorf("Expected a sell stop to stop down")
+	}
+
+	var decoded map[string]map[string]json.RawMessage
+	b, _ = req.ToJson()
+	_ = json.Unmarshal(b, &decoded)
+	if len(decoded["order_configuration"]) != 1 {
+		t.Errorf("Expected exactly one configuration, got %s", b)
+	}
+}
+
+func TestOrderBuilder_PlainSize(t *testing.T) {
+	req, err := MarketSell("BTC-USD", MustDecimal("1e-3")).BuildFor(testProduct())
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	b, _ := json.Marshal(req.OrderConfiguration)
+	want := `{"market_market_ioc":{"base_size":"0.001"}}`
+	if string(b) != want {
+		t.Errorf("Expected %s, got %s", want, b)
+	}
+}
+
+func TestOrderBuilder_Options(t *testing.T) {
+	end := time.Now().Add(time.Hour)
+	req, err := LimitSellGtd("BTC-USD", "0.1", "40000", end).PostOnly().Build()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !req.OrderConfiguration.LimitLimitGtd.PostOnly {
+		t.Errorf("Expected post only to be set")
+	}
+
+	if _, err = MarketSell("BTC-USD", "0.1").PostOnly().Build(); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected post only to be rejected on a market order, got %v", err)
+	}
+	if _, err = LimitBuyGtc("BTC-USD", "0.1", "10").StopDirection(StopDirectionStopUp).Build(); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected a stop direction to be rejected on a limit order, got %v", err)
+	}
+	if _, err = LimitBuyGtd("BTC-USD", "0.1", "10", time.Now().Add(-time.Minute)).Build(); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected an end time in the past to be rejected, got %v", err)
+	}
+	if _, err = LimitBuyGtc("BTC-USD", "-1", "10").Build(); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected a negative size to be rejected, got %v", err)
+	}
+}
+
+func TestOrderConfiguration_Validate(t *testing.T) {
+	if err := (OrderConfiguration{}).Validate(); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected an empty configuration to be rejected, got %v", err)
+	}
+
+	cfg := OrderConfiguration{
+		MarketMarketIoc: &MarketMarketIoc{QuoteSize: "10"},
+		LimitLimitGtc:   &LimitLimitGtc{BaseSize: "1", LimitPrice: "10"},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "only one") {
+		t.Errorf("Expected two configurations to be rejected, got %v", err)
+	}
+
+	cfg = OrderConfiguration{MarketMarketIoc: &MarketMarketIoc{QuoteSize: "10", BaseSize: "1"}}
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected both market sizes to be rejected, got %v", err)
+	}
+}
+
+func TestCreateOrderRequest_Validate(t *testing.T) {
+	checks := []struct {
+		name    string
+		builder *OrderBuilder
+		product func(p *Product)
+		valid   bool
+	}{
+		{"limit", LimitBuyGtc("BTC-USD", "0.001", "30000.01"), nil, true},
+		{"market buy", MarketBuy("BTC-USD", "25.50"), nil, true},
+		{"base increment", LimitBuyGtc("BTC-USD", "0.000000001", "30000"), nil, false},
+		{"price increment", LimitBuyGtc("BTC-USD", "0.001", "30000.001"), nil, false},
+		{"price increment override", LimitBuyGtc("BTC-USD", "0.001", "30000.01"), func(p *Product) { p.PriceIncrement = "0.5" }, false},
+		{"stop price increment", StopLimitBuyGtc("BTC-USD", "0.001", "30000", "29999.999"), nil, false},
+		{"base min", LimitBuyGtc("BTC-USD", "0.000001", "30000"), nil, false},
+		{"base max", MarketSell("BTC-USD", "3400.1"), nil, false},
+		{"quote min", MarketBuy("BTC-USD", "0.99"), nil, false},
+		{"notional min", LimitBuyGtc("BTC-USD", "0.00001", "10"), nil, false},
+		{"wrong product", LimitBuyGtc("ETH-USD", "0.001", "2000"), nil, false},
+		{"cancel only", LimitBuyGtc("BTC-USD", "0.001", "30000"), func(p *Product) { p.CancelOnly = true }, false},
+		{"limit only", MarketBuy("BTC-USD", "10"), func(p *Product) { p.LimitOnly = true }, false},
+		{"limit only limit", LimitBuyGtc("BTC-USD", "0.001", "30000"), func(p *Product) { p.LimitOnly = true }, true},
+		{"post only", LimitBuyGtc("BTC-USD", "0.001", "30000"), func(p *Product) { p.PostOnly = true }, false},
+		{"post only post", LimitBuyGtc("BTC-USD", "0.001", "30000").PostOnly(), func(p *Product) { p.PostOnly = true }, true},
+		{"trading disabled", LimitBuyGtc("BTC-USD", "0.001", "30000"), func(p *Product) { p.TradingDisabled = true }, false},
+		{"offline", LimitBuyGtc("BTC-USD", "0.001", "30000"), func(p *Product) { p.Status = "delisted" }, false},
+	}
+
+	for _, c := range checks {
+		p := testProduct()
+		if c.product != nil {
+			c.product(&p)
+		}
+		_, err := c.builder.BuildFor(p)
+		if c.valid && err != nil {
+			t.Errorf("%s: Expected no error, got %s", c.name, err)
+		}
+		if !c.valid && !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("%s: Expected ErrInvalidOrder, got %v", c.name, err)
+		}
+	}
+}
+
+func TestOrderBuilder_AdvancedOrderTypes(t *testing.T) {
+	start := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+
+	checks := []struct {
+		builder *OrderBuilder
+		want    string
+	}{
+		{SorLimitBuyIoc("BTC-USD", "0.001", "30000"), `{"sor_limit_ioc":{"base_size":"0.001","limit_price":"30000"}}`},
+		{LimitSellFok("BTC-USD", "0.001", "30000"), `{"limit_limit_fok":{"base_size":"0.001","limit_price":"30000"}}`},
+		{TriggerBracketSellGtc("BTC-USD", "0.001", "35000", "28000"), `{"trigger_bracket_gtc":{"base_size":"0.001","limit_price":"35000","stop_trigger_price":"28000"}}`},
+		{TriggerBracketBuyGtd("BTC-USD", "0.001", "30000", "32000", end), `{"trigger_bracket_gtd":{"base_size":"0.001","limit_price":"30000","stop_trigger_price":"32000","end_time":"` + end.Format(time.RFC3339) + `"}}`},
+		{TwapLimitBuyGtd("BTC-USD", "0.1", "31000", start, end).Buckets(24), `{"twap_limit_gtd":{"base_size":"0.1","start_time":"` + start.Format(time.RFC3339) + `","end_time":"` + end.Format(time.RFC3339) + `","limit_price":"31000","number_buckets":"24"}}`},
+	}
+
+	for _, c := range checks {
+		req, err := c.builder.BuildFor(testProduct())
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err)
+		}
+		b, _ := json.Marshal(req.OrderConfiguration)
+		if string(b) != c.want {
+			t.Errorf("Expected %s, got %s", c.want, b)
+		}
+	}
+
+	if _, err := TwapLimitSellGtd("BTC-USD", "0.1", "31000", end, start).Build(); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected an end time before the start time to be rejected, got %v", err)
+	}
+	if _, err := LimitBuyFok("BTC-USD", "0.1", "31000").Buckets(2).Build(); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected buckets to be rejected on a fok order, got %v", err)
+	}
+	if _, err := TriggerBracketSellGtc("BTC-USD", "0.1", "35000", "0").Build(); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected a missing stop trigger price to be rejected, got %v", err)
+	}
+}
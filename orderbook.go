@@ -0,0 +1,424 @@
+package coinbasev3
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	ErrOrderBookNoSnapshot   = fmt.Errorf("order book has no snapshot")
+	ErrInvalidBookSide       = fmt.Errorf("invalid order book side")
+	ErrInvalidBookLevel      = fmt.Errorf("invalid order book level")
+	ErrInsufficientLiquidity = fmt.Errorf("insufficient liquidity")
+)
+
+// vwapPlaces is the number of decimal places VWAP rounds down to.
+const vwapPlaces = 16
+
+// OrderBookLevel is the total size resting at a single price.
+type OrderBookLevel struct {
+	Price Decimal
+	Size  Decimal
+}
+
+// OrderBook is a local order book fed by level2 websocket events. It keeps the bids and asks of every product sorted by price, so updates and best price lookups take O(log n). It is safe for concurrent use.
+//
+//	book := coinbasev3.NewOrderBook()
+//	for msg := range readCh {
+//		evt, _ := event.GetLevel2Event()
+//		if err := book.Apply(evt); errors.Is(err, coinbasev3.ErrOrderBookNoSnapshot) {
+//			_ = client.SeedOrderBook(book, "BTC-USD", 0)
+//		}
+//	}
+type OrderBook struct {
+	mu       sync.RWMutex
+	products map[string]*productBook
+	rnd      *rand.Rand
+}
+
+type productBook struct {
+	bids      *bookSide
+	asks      *bookSide
+	updatedAt time.Time
+}
+
+// NewOrderBook creates an empty order book.
+func NewOrderBook() *OrderBook {
+	return &OrderBook{
+		products: make(map[string]*productBook),
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (b *OrderBook) newProductBook() *productBook {
+	return &productBook{
+		bids: &bookSide{desc: true, rnd: b.rnd},
+		asks: &bookSide{rnd: b.rnd},
+	}
+}
+
+// Apply applies the snapshots and updates of a level2 event. A snapshot replaces the book of its product. The whole event is validated before anything is applied, so an invalid event leaves every book unchanged. An update for a product without a snapshot returns ErrOrderBookNoSnapshot; seed the product with Seed or SeedOrderBook and apply the following events.
+func (b *OrderBook) Apply(evt Level2Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.validate(evt); err != nil {
+		return err
+	}
+
+	for _, e := range evt.Events {
+		var pb *productBook
+		switch e.Type {
+		case "snapshot":
+			pb = b.newProductBook()
+			b.products[e.ProductId] = pb
+		case "update":
+			pb = b.products[e.ProductId]
+		default:
+			continue
+		}
+
+		for _, u := range e.Updates {
+			side := pb.asks
+			if u.Side == "bid" {
+				side = pb.bids
+			}
+			side.set(u.PriceLevel, u.NewQuantity)
+			if u.EventTime.After(pb.updatedAt) {
+				pb.updatedAt = u.EventTime
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks that every update of an event can be applied. Must be called with the lock held.
+func (b *OrderBook) validate(evt Level2Event) error {
+	// a snapshot earlier in the same event makes the following updates of its product valid
+	snapshots := make(map[string]bool)
+	for _, e := range evt.Events {
+		switch e.Type {
+		case "snapshot":
+			snapshots[e.ProductId] = true
+		case "update":
+			if _, ok := b.products[e.ProductId]; !ok && !snapshots[e.ProductId] {
+				return fmt.Errorf("%w: %s", ErrOrderBookNoSnapshot, e.ProductId)
+			}
+		default:
+			continue
+		}
+
+		for _, u := range e.Updates {
+			switch u.Side {
+			case "bid", "offer", "ask":
+			default:
+				return fmt.Errorf("%w: %s on %s", ErrInvalidBookSide, u.Side, e.ProductId)
+			}
+			if err := validateBookLevel(e.ProductId, u.PriceLevel, u.NewQuantity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateBookLevel checks that a level has a valid price and a valid, non-negative size. Malformed decimals would otherwise be treated as zero, adding a level at price 0 or deleting a real one.
+func validateBookLevel(productId string, price, size Decimal) error {
+	if !price.IsValid() || !size.IsValid() || size.Sign() < 0 {
+		return fmt.Errorf("%w: %q at %q on %s", ErrInvalidBookLevel, size, price, productId)
+	}
+	return nil
+}
+
+// Seed replaces the book of a product with a price book returned by GetProductBook. A book with an invalid level returns ErrInvalidBookLevel and leaves the current book unchanged.
+func (b *OrderBook) Seed(productId string, book PriceBook) error {
+	for _, levels := range [][]PriceBookOrder{book.Bids, book.Asks} {
+		for _, level := range levels {
+			if err := validateBookLevel(productId, level.Price, level.Size); err != nil {
+				return err
+			}
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pb := b.newProductBook()
+	for _, level := range book.Bids {
+		pb.bids.set(level.Price, level.Size)
+	}
+	for _, level := range book.Asks {
+		pb.asks.set(level.Price, level.Size)
+	}
+	pb.updatedAt = book.Time
+	b.products[productId] = pb
+	return nil
+}
+
+// SeedOrderBook seeds the book of a product from GetProductBook, for when the level2 snapshot was missed. Levels that changed between the request and the next update may be stale until they are updated again.
+func (c *ApiClient) SeedOrderBook(book *OrderBook, productId string, limit int32) error {
+	return c.SeedOrderBookWithContext(context.Background(), book, productId, limit)
+}
+
+// SeedOrderBookWithContext is like SeedOrderBook but binds the request to the given context.
+func (c *ApiClient) SeedOrderBookWithContext(ctx context.Context, book *OrderBook, productId string, limit int32) error {
+	data, err := c.GetProductBookWithContext(ctx, productId, limit)
+	if err != nil {
+		return err
+	}
+	return book.Seed(productId, data.PriceBook)
+}
+
+// HasSnapshot returns true if the book of a product was seeded by a snapshot or Seed.
+func (b *OrderBook) HasSnapshot(productId string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.products[productId]
+	return ok
+}
+
+// UpdatedAt returns the event time of the latest update applied to the book of a product.
+func (b *OrderBook) UpdatedAt(productId string) time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if pb, ok := b.products[productId]; ok {
+		return pb.updatedAt
+	}
+	return time.Time{}
+}
+
+// BestBid returns the highest bid of a product. It returns false when the product has no bids.
+func (b *OrderBook) BestBid(productId string) (OrderBookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if pb, ok := b.products[productId]; ok {
+		return pb.bids.first()
+	}
+	return OrderBookLevel{}, false
+}
+
+// BestAsk returns the lowest ask of a product. It returns false when the product has no asks.
+func (b *OrderBook) BestAsk(productId string) (OrderBookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if pb, ok := b.products[productId]; ok {
+		return pb.asks.first()
+	}
+	return OrderBookLevel{}, false
+}
+
+// Depth returns up to n of the best bids and asks of a product, best price first. A zero or negative n returns every level.
+func (b *OrderBook) Depth(productId string, n int) (bids, asks []OrderBookLevel) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	pb, ok := b.products[productId]
+	if !ok {
+		return nil, nil
+	}
+	return pb.bids.levels(n), pb.asks.levels(n)
+}
+
+// Mid returns the midpoint between the best bid and ask of a product. It returns false when either side is empty.
+func (b *OrderBook) Mid(productId string) (Decimal, bool) {
+	bid, ask, ok := b.top(productId)
+	if !ok {
+		return "", false
+	}
+	return bid.Price.Add(ask.Price).Mul("0.5"), true
+}
+
+// Spread returns the best ask minus the best bid of a product. It returns false when either side is empty.
+func (b *OrderBook) Spread(productId string) (Decimal, bool) {
+	bid, ask, ok := b.top(productId)
+	if !ok {
+		return "", false
+	}
+	return ask.Price.Sub(bid.Price), true
+}
+
+func (b *OrderBook) top(productId string) (bid, ask OrderBookLevel, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	pb, found := b.products[productId]
+	if !found {
+		return bid, ask, false
+	}
+	bid, bidOk := pb.bids.first()
+	ask, askOk := pb.asks.first()
+	return bid, ask, bidOk && askOk
+}
+
+// VWAP returns the volume weighted average price of filling size in the base currency at market. A buy walks the asks and a sell walks the bids. It returns ErrInsufficientLiquidity when the book is not deep enough.
+func (b *OrderBook) VWAP(productId string, side OrderSide, size Decimal) (Decimal, error) {
+	if size.Sign() <= 0 {
+		return "", fmt.Errorf("%w: size must be positive, got %s", ErrInvalidDecimal, size)
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	pb, ok := b.products[productId]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrOrderBookNoSnapshot, productId)
+	}
+
+	var levels *bookSide
+	switch side {
+	case OrderSideBuy:
+		levels = pb.asks
+	case OrderSideSell:
+		levels = pb.bids
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidBookSide, side)
+	}
+
+	remaining := size
+	notional := Decimal("0")
+	levels.each(func(level OrderBookLevel) bool {
+		take := level.Size
+		if remaining.LessThan(take) {
+			take = remaining
+		}
+		notional = notional.Add(level.Price.Mul(take))
+		remaining = remaining.Sub(take)
+		return remaining.Sign() > 0
+	})
+	if remaining.Sign() > 0 {
+		return "", fmt.Errorf("%w: %s short of %s on %s", ErrInsufficientLiquidity, remaining, size, productId)
+	}
+	return notional.Div(size, vwapPlaces)
+}
+
+// bookSide is one side of a product's book, kept in a treap ordered by price: ascending for asks and descending for bids.
+type bookSide struct {
+	root *bookNode
+	desc bool
+	rnd  *rand.Rand
+}
+
+type bookNode struct {
+	level       OrderBookLevel
+	priority    uint32
+	left, right *bookNode
+}
+
+func (s *bookSide) cmp(a, b Decimal) int {
+	if s.desc {
+		return b.Cmp(a)
+	}
+	return a.Cmp(b)
+}
+
+// set sets the size at a price, removing the level when the size is zero.
+func (s *bookSide) set(price, size Decimal) {
+	if size.Sign() <= 0 {
+		s.root = s.remove(s.root, price)
+		return
+	}
+	s.root = s.insert(s.root, OrderBookLevel{Price: price, Size: size})
+}
+
+func (s *bookSide) insert(n *bookNode, level OrderBookLevel) *bookNode {
+	if n == nil {
+		return &bookNode{level: level, priority: s.rnd.Uint32()}
+	}
+	switch c := s.cmp(level.Price, n.level.Price); {
+	case c == 0:
+		n.level.Size = level.Size
+	case c < 0:
+		n.left = s.insert(n.left, level)
+		if n.left.priority > n.priority {
+			n = rotateRight(n)
+		}
+	default:
+		n.right = s.insert(n.right, level)
+		if n.right.priority > n.priority {
+			n = rotateLeft(n)
+		}
+	}
+	return n
+}
+
+func (s *bookSide) remove(n *bookNode, price Decimal) *bookNode {
+	if n == nil {
+		return nil
+	}
+	switch c := s.cmp(price, n.level.Price); {
+	case c < 0:
+		n.left = s.remove(n.left, price)
+	case c > 0:
+		n.right = s.remove(n.right, price)
+	default:
+		return mergeNodes(n.left, n.right)
+	}
+	return n
+}
+
+func (s *bookSide) first() (OrderBookLevel, bool) {
+	n := s.root
+	if n == nil {
+		return OrderBookLevel{}, false
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.level, true
+}
+
+// each calls fn with every level, best price first, until fn returns false.
+func (s *bookSide) each(fn func(OrderBookLevel) bool) {
+	var stack []*bookNode
+	n := s.root
+	for n != nil || len(stack) > 0 {
+		for n != nil {
+			stack = append(stack, n)
+			n = n.left
+		}
+		n = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !fn(n.level) {
+			return
+		}
+		n = n.right
+	}
+}
+
+func (s *bookSide) levels(max int) []OrderBookLevel {
+	var levels []OrderBookLevel
+	s.each(func(level OrderBookLevel) bool {
+		levels = append(levels, level)
+		return max <= 0 || len(levels) < max
+	})
+	return levels
+}
+
+func rotateRight(n *bookNode) *bookNode {
+	l := n.left
+	n.left, l.right = l.right, n
+	return l
+}
+
+func rotateLeft(n *bookNode) *bookNode {
+	r := n.right
+	n.right, r.left = r.left, n
+	return r
+}
+
+// mergeNodes joins two treaps where every price in a sorts before every price in b.
+func mergeNodes(a, b *bookNode) *bookNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		a.right = mergeNodes(a.right, b)
+		return a
+	}
+	b.left = mergeNodes(a, b.left)
+	return b
+}
@@ -0,0 +1,244 @@
+package coinbasev3
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func level2Event(typ, productId string, updates ...Level2Update) Level2Event {
+	return Level2Event{Events: []Level2EventType{{Type: typ, ProductId: productId, Updates: updates}}}
+}
+
+func level2Update(side string, price, size Decimal) Level2Update {
+	return Level2Update{Side: side, EventTime: time.Now(), PriceLevel: price, NewQuantity: size}
+}
+
+func TestOrderBook_Apply(t *testing.T) {
+	book := NewOrderBook()
+
+	if err := book.Apply(level2Event("update", "BTC-USD", level2Update("bid", "100", "1"))); !errors.Is(err, ErrOrderBookNoSnapshot) {
+		t.Fatalf("Expected ErrOrderBookNoSnapshot, got %v", err)
+	}
+
+	err := book.Apply(level2Event("snapshot", "BTC-USD",
+		level2Update("bid", "99.5", "2"),
+		level2Update("bid", "100", "1"),
+		level2Update("bid", "98", "5"),
+		level2Update("offer", "101", "0.5"),
+		level2Update("offer", "100.5", "1.5"),
+		level2Update("offer", "103", "4"),
+	))
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	bid, _ := book.BestBid("BTC-USD")
+	ask, _ := book.BestAsk("BTC-USD")
+	if bid.Price != "100" || ask.Price != "100.5" {
+		t.Errorf("Expected best bid 100 and ask 100.5, got %s and %s", bid.Price, ask.Price)
+	}
+
+	// remove the best bid, resize an ask and add a new best ask
+	err = book.Apply(level2Event("update", "BTC-USD",
+		level2Update("bid", "100", "0"),
+		level2Update("offer", "101", "3"),
+		level2Update("offer", "100.25", "0.1"),
+	))
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	bids, asks := book.Depth("BTC-USD", 3)
+	expectedBids := []OrderBookLevel{{"99.5", "2"}, {"98", "5"}}
+	expectedAsks := []OrderBookLevel{{"100.25", "0.1"}, {"100.5", "1.5"}, {"101", "3"}}
+	if fmt.Sprint(bids) != fmt.Sprint(expectedBids) {
+		t.Errorf("Expected bids %v, got %v", expectedBids, bids)
+	}
+	if fmt.Sprint(asks) != fmt.Sprint(expectedAsks) {
+		t.Errorf("Expected asks %v, got %v", expectedAsks, asks)
+	}
+
+	if mid, _ := book.Mid("BTC-USD"); !mid.Equal("99.875") {
+		t.Errorf("Expected mid 99.875, got %s", mid)
+	}
+	if spread, _ := book.Spread("BTC-USD"); !spread.Equal("0.75") {
+		t.Errorf("Expected spread 0.75, got %s", spread)
+	}
+
+	// a snapshot replaces the whole book
+	if err := book.Apply(level2Event("snapshot", "BTC-USD", level2Update("bid", "90", "1"))); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if _, ok := book.BestAsk("BTC-USD"); ok {
+		t.Error("Expected no asks after the snapshot")
+	}
+	if _, ok := book.Mid("BTC-USD"); ok {
+		t.Error("Expected no mid with an empty side")
+	}
+
+	if err := book.Apply(level2Event("update", "BTC-USD", level2Update("middle", "90", "1"))); !errors.Is(err, ErrInvalidBookSide) {
+		t.Errorf("Expected ErrInvalidBookSide, got %v", err)
+	}
+}
+
+func TestOrderBook_VWAP(t *testing.T) {
+	book := NewOrderBook()
+	book.Seed("BTC-USD", PriceBook{
+		Bids: []PriceBookOrder{{Price: "99", Size: "1"}, {Price: "98", Size: "2"}},
+		Asks: []PriceBookOrder{{Price: "101", Size: "1"}, {Price: "102", Size: "2"}},
+	})
+
+	vwap, err := book.VWAP("BTC-USD", OrderSideBuy, "2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !vwap.Equal("101.5") {
+		t.Errorf("Expected buy vwap 101.5, got %s", vwap)
+	}
+
+	vwap, err = book.VWAP("BTC-USD", OrderSideSell, "3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !vwap.Equal("98.3333333333333333") {
+		t.Errorf("Expected sell vwap 98.3333333333333333, got %s", vwap)
+	}
+
+	if _, err := book.VWAP("BTC-USD", OrderSideBuy, "3.5"); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("Expected ErrInsufficientLiquidity, got %v", err)
+	}
+	if _, err := book.VWAP("ETH-USD", OrderSideBuy, "1"); !errors.Is(err, ErrOrderBookNoSnapshot) {
+		t.Errorf("Expected ErrOrderBookNoSnapshot, got %v", err)
+	}
+}
+
+func TestOrderBook_Sorted(t *testing.T) {
+	book := NewOrderBook()
+	if err := book.Apply(level2Event("snapshot", "BTC-USD")); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	live := make(map[int]bool)
+	for i := 0; i < 5000; i++ {
+		price := rnd.Intn(500)
+		size := Decimal("1")
+		if rnd.Intn(3) == 0 {
+			size = "0"
+			delete(live, price)
+		} else {
+			live[price] = true
+		}
+		if err := book.Apply(level2Event("update", "BTC-USD", level2Update("offer", NewDecimalFromInt(int64(price)), size))); err != nil {
+			t.Fatalf("Expected no error, got %s", err)
+		}
+	}
+
+	var expected []int
+	for price := range live {
+		expected = append(expected, price)
+	}
+	sort.Ints(expected)
+
+	_, asks := book.Depth("BTC-USD", 0)
+	if len(asks) != len(expected) {
+		t.Fatalf("Expected %d asks, got %d", len(expected), len(asks))
+	}
+	for i, level := range asks {
+		if !level.Price.Equal(NewDecimalFromInt(int64(expected[i]))) {
+			t.Fatalf("Expected ask %d at %d, got %s", i, expected[i], level.Price)
+		}
+	}
+}
+
+func TestApiClient_SeedOrderBook(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/product_book", "product_id=BTC-USD&limit=50",
+		jsonResponder(`{"pricebook":{"product_id":"BTC-USD","bids":[{"price":"41999","size":"1.5"}],"asks":[{"price":"42001","size":"0.2"}],"time":"2023-11-28T16:56:43.770106Z"}}`))
+
+	book := NewOrderBook()
+	if err := api.SeedOrderBook(book, "BTC-USD", 50); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !book.HasSnapshot("BTC-USD") {
+		t.Fatal("Expected the book to be seeded")
+	}
+	if spread, _ := book.Spread("BTC-USD"); !spread.Equal("2") {
+		t.Errorf("Expected spread 2, got %s", spread)
+	}
+
+	// updates apply on top of the seeded book
+	if err := book.Apply(level2Event("update", "BTC-USD", level2Update("offer", "42000", "1"))); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if ask, _ := book.BestAsk("BTC-USD"); ask.Price != "42000" {
+		t.Errorf("Expected best ask 42000, got %s", ask.Price)
+	}
+}
+
+func TestOrderBook_Apply_InvalidEventLeavesBookUnchanged(t *testing.T) {
+	book := NewOrderBook()
+	book.Seed("BTC-USD", PriceBook{
+		Bids: []PriceBookOrder{{Price: "99", Size: "1"}},
+		Asks: []PriceBookOrder{{Price: "101", Size: "1"}},
+	})
+
+	evt := Level2Event{Events: []Level2EventType{
+		{Type: "snapshot", ProductId: "ETH-USD", Updates: []Level2Update{level2Update("bid", "2000", "1")}},
+		{Type: "update", ProductId: "BTC-USD", Updates: []Level2Update{
+			level2Update("bid", "100", "2"),
+			level2Update("middle", "100.5", "1"),
+			level2Update("offer", "101", "0"),
+		}},
+	}}
+	err := book.Apply(evt)
+	if !errors.Is(err, ErrInvalidBookSide) {
+		t.Fatalf("Expected ErrInvalidBookSide, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "BTC-USD") {
+		t.Errorf("Expected the error to name the product, got %s", err)
+	}
+
+	if book.HasSnapshot("ETH-USD") {
+		t.Error("Expected the ETH-USD snapshot not to be applied")
+	}
+	bids, asks := book.Depth("BTC-USD", 0)
+	if fmt.Sprint(bids) != fmt.Sprint([]OrderBookLevel{{"99", "1"}}) || fmt.Sprint(asks) != fmt.Sprint([]OrderBookLevel{{"101", "1"}}) {
+		t.Errorf("Expected the BTC-USD book to be unchanged, got bids %v and asks %v", bids, asks)
+	}
+
+	for _, u := range []Level2Update{level2Update("bid", "", "1"), level2Update("offer", "101", "abc"), level2Update("offer", "101", "-1")} {
+		err := book.Apply(Level2Event{Events: []Level2EventType{{Type: "update", ProductId: "BTC-USD", Updates: []Level2Update{u}}}})
+		if !errors.Is(err, ErrInvalidBookLevel) {
+			t.Errorf("Expected ErrInvalidBookLevel for %q at %q, got %v", u.NewQuantity, u.PriceLevel, err)
+		}
+	}
+	err = book.Seed("BTC-USD", PriceBook{Bids: []PriceBookOrder{{Price: "100", Size: "1"}}, Asks: []PriceBookOrder{{Price: "x", Size: "1"}}})
+	if !errors.Is(err, ErrInvalidBookLevel) {
+		t.Errorf("Expected ErrInvalidBookLevel, got %v", err)
+	}
+	bids, asks = book.Depth("BTC-USD", 0)
+	if fmt.Sprint(bids) != fmt.Sprint([]OrderBookLevel{{"99", "1"}}) || fmt.Sprint(asks) != fmt.Sprint([]OrderBookLevel{{"101", "1"}}) {
+		t.Errorf("Expected the BTC-USD book to be unchanged, got bids %v and asks %v", bids, asks)
+	}
+
+	// an update after a snapshot of its product in the same event is valid
+	evt = Level2Event{Events: []Level2EventType{
+		{Type: "snapshot", ProductId: "ETH-USD", Updates: []Level2Update{level2Update("bid", "2000", "1")}},
+		{Type: "update", ProductId: "ETH-USD", Updates: []Level2Update{level2Update("offer", "2001", "1")}},
+	}}
+	if err := book.Apply(evt); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if spread, _ := book.Spread("ETH-USD"); !spread.Equal("1") {
+		t.Errorf("Expected spread 1, got %s", spread)
+	}
+}
@@ -1,9 +1,12 @@
 package coinbasev3
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -15,6 +18,8 @@ const (
 	OrderTypeLimit     OrderType = "LIMIT"
 	OrderTypeStop      OrderType = "STOP"
 	OrderTypeStopLimit OrderType = "STOP_LIMIT"
+	OrderTypeBracket   OrderType = "BRACKET"
+	OrderTypeTwap      OrderType = "TWAP"
 	OrderTypeUnknown   OrderType = "UNKNOWN_ORDER_TYPE"
 )
 
@@ -26,6 +31,13 @@ const (
 	OrderSideUnknown OrderSide = "UNKNOWN_ORDER_SIDE"
 )
 
+type StopDirection string
+
+const (
+	StopDirectionStopUp   StopDirection = "STOP_DIRECTION_STOP_UP"
+	StopDirectionStopDown StopDirection = "STOP_DIRECTION_STOP_DOWN"
+)
+
 type OrderPlacementSource string
 
 const (
@@ -47,6 +59,8 @@ type ListFillsQuery struct {
 	Limit int64 `json:"limit"`
 	// Cursor string Cursor used for pagination. When provided, the response returns responses after this cursor.
 	Cursor string `json:"cursor"`
+	// RetailPortfolioId string Only fills of this portfolio are returned. Defaults to the default portfolio.
+	RetailPortfolioId string `json:"retail_portfolio_id"`
 }
 
 // BuildQueryString creates a query string from the request parameters. If no parameters are set, an empty string is returned.
@@ -70,6 +84,9 @@ func (q ListFillsQuery) BuildQueryString() string {
 	if q.Cursor != "" {
 		sb.WriteString(fmt.Sprintf("&cursor=%s", q.Cursor))
 	}
+	if q.RetailPortfolioId != "" {
+		sb.WriteString(fmt.Sprintf("&retail_portfolio_id=%s", q.RetailPortfolioId))
+	}
 
 	// Better way to do this?
 	if sb.Len() > 0 {
@@ -80,10 +97,15 @@ func (q ListFillsQuery) BuildQueryString() string {
 
 // GetListFills get a list of fills filtered by optional query parameters (product_id, order_id, etc).
 func (c *ApiClient) GetListFills(q ListFillsQuery) (ListFillsData, error) {
+	return c.GetListFillsWithContext(context.Background(), q)
+}
+
+// GetListFillsWithContext is like GetListFills but binds the request to the given context.
+func (c *ApiClient) GetListFillsWithContext(ctx context.Context, q ListFillsQuery) (ListFillsData, error) {
 	u := c.makeV3Url(fmt.Sprintf("/brokerage/orders/historical/fills%s", q.BuildQueryString()))
 	var data ListFillsData
-	if res, err := c.get(u, &data); err != nil {
-		return data, newResponseError(res)
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
 	}
 	return data, nil
 }
@@ -121,9 +143,9 @@ type Fill struct {
 	OrderId            string    `json:"order_id"`
 	TradeTime          time.Time `json:"trade_time"`
 	TradeType          string    `json:"trade_type"`
-	Price              string    `json:"price"`
-	Size               string    `json:"size"`
-	Commission         string    `json:"commission"`
+	Price              Decimal   `json:"price"`
+	Size               Decimal   `json:"size"`
+	Commission         Decimal   `json:"commission"`
 	ProductId          string    `json:"product_id"`
 	SequenceTimestamp  time.Time `json:"sequence_timestamp"`
 	LiquidityIndicator string    `json:"liquidity_indicator"`
@@ -156,6 +178,8 @@ type ListOrdersQuery struct {
 	OrderPlacementSource OrderPlacementSource `json:"order_placement_source,omitempty"`
 	// ContractExpiryType Only orders matching this contract expiry type are returned. Filter is only applied if ProductType is set to FUTURE in the request.
 	ContractExpiryType ContractExpiryType `json:"contract_expiry_type,omitempty"`
+	// RetailPortfolioId Only orders of this portfolio are returned. Defaults to the default portfolio.
+	RetailPortfolioId string `json:"retail_portfolio_id,omitempty"`
 }
 
 // BuildQueryString creates a query string from the request parameters. If no parameters are set, an empty string is returned.
@@ -197,21 +221,29 @@ func (q *ListOrdersQuery) BuildQueryString() string {
 	if q.ContractExpiryType != "" {
 		sb.WriteString(fmt.Sprintf("&contract_expiry_type=%s", q.ContractExpiryType))
 	}
+	if q.RetailPortfolioId != "" {
+		sb.WriteString(fmt.Sprintf("&retail_portfolio_id=%s", q.RetailPortfolioId))
+	}
 
 	// Remove the first '&' for a clean query string
 	if sb.Len() > 0 {
-		return sb.String()[1:]
+		return fmt.Sprintf("?%s", sb.String()[1:])
 	}
 	return ""
 }
 
 // GetListOrders get a list of orders filtered by optional query parameters (product_id, order_status, etc). Note: You cannot pair open orders with other order types. Example: order_status=OPEN,CANCELLED will return an error.
 func (c *ApiClient) GetListOrders(q ListOrdersQuery) (ListOrdersData, error) {
+	return c.GetListOrdersWithContext(context.Background(), q)
+}
+
+// GetListOrdersWithContext is like GetListOrders but binds the request to the given context.
+func (c *ApiClient) GetListOrdersWithContext(ctx context.Context, q ListOrdersQuery) (ListOrdersData, error) {
 	u := c.makeV3Url(fmt.Sprintf("/brokerage/orders/historical/batch%s", q.BuildQueryString()))
 
 	var data ListOrdersData
-	if res, err := c.get(u, &data); err != nil {
-		return data, newResponseError(res)
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
 	}
 	return data, nil
 }
@@ -255,17 +287,17 @@ type Order struct {
 	Status                string             `json:"status"`
 	TimeInForce           string             `json:"time_in_force"`
 	CreatedTime           time.Time          `json:"created_time"`
-	CompletionPercentage  string             `json:"completion_percentage"`
-	FilledSize            string             `json:"filled_size"`
-	AverageFilledPrice    string             `json:"average_filled_price"`
-	Fee                   string             `json:"fee"`
-	NumberOfFills         string             `json:"number_of_fills"`
-	FilledValue           string             `json:"filled_value"`
+	CompletionPercentage  Decimal            `json:"completion_percentage"`
+	FilledSize            Decimal            `json:"filled_size"`
+	AverageFilledPrice    Decimal            `json:"average_filled_price"`
+	Fee                   Decimal            `json:"fee"`
+	NumberOfFills         int                `json:"number_of_fills"`
+	FilledValue           Decimal            `json:"filled_value"`
 	PendingCancel         bool               `json:"pending_cancel"`
 	SizeInQuote           bool               `json:"size_in_quote"`
-	TotalFees             string             `json:"total_fees"`
+	TotalFees             Decimal            `json:"total_fees"`
 	SizeInclusiveOfFees   bool               `json:"size_inclusive_of_fees"`
-	TotalValueAfterFees   string             `json:"total_value_after_fees"`
+	TotalValueAfterFees   Decimal            `json:"total_value_after_fees"`
 	TriggerStatus         string             `json:"trigger_status"`
 	OrderType             string             `json:"order_type"`
 	RejectReason          string             `json:"reject_reason"`
@@ -274,66 +306,206 @@ type Order struct {
 	RejectMessage         string             `json:"reject_message"`
 	CancelMessage         string             `json:"cancel_message"`
 	OrderPlacementSource  string             `json:"order_placement_source"`
-	OutstandingHoldAmount string             `json:"outstanding_hold_amount"`
+	OutstandingHoldAmount Decimal            `json:"outstanding_hold_amount"`
 	IsLiquidation         string             `json:"is_liquidation"`
-	LastFillTime          string             `json:"last_fill_time"`
+	LastFillTime          time.Time          `json:"last_fill_time"`
 	EditHistory           []EditHistory      `json:"edit_history"`
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase sends the number of fills as a string and an empty last fill time for unfilled orders.
+func (o *Order) UnmarshalJSON(data []byte) error {
+	type alias Order
+	aux := struct {
+		NumberOfFills flexInt  `json:"number_of_fills"`
+		LastFillTime  flexTime `json:"last_fill_time"`
+		*alias
+	}{alias: (*alias)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.NumberOfFills = int(aux.NumberOfFills)
+	o.LastFillTime = time.Time(aux.LastFillTime)
+	return nil
+}
+
 type EditHistory struct {
-	Price                  string `json:"price"`
-	Size                   string `json:"size"`
-	ReplaceAcceptTimestamp string `json:"replace_accept_timestamp"`
+	Price                  Decimal   `json:"price"`
+	Size                   Decimal   `json:"size"`
+	ReplaceAcceptTimestamp time.Time `json:"replace_accept_timestamp"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because the timestamp is empty until the edit is accepted.
+func (e *EditHistory) UnmarshalJSON(data []byte) error {
+	type alias EditHistory
+	aux := struct {
+		ReplaceAcceptTimestamp flexTime `json:"replace_accept_timestamp"`
+		*alias
+	}{alias: (*alias)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	e.ReplaceAcceptTimestamp = time.Time(aux.ReplaceAcceptTimestamp)
+	return nil
 }
 
+// OrderConfiguration holds the configuration of an order. Exactly one of the fields must be set; use the order builders such as MarketBuy or LimitSellGtc to create one.
 type OrderConfiguration struct {
-	MarketMarketIoc       MarketMarketIoc       `json:"market_market_ioc"`
-	LimitLimitGtc         LimitLimitGtc         `json:"limit_limit_gtc"`
-	LimitLimitGtd         LimitLimitGtd         `json:"limit_limit_gtd"`
-	StopLimitStopLimitGtc StopLimitStopLimitGtc `json:"stop_limit_stop_limit_gtc"`
-	StopLimitStopLimitGtd StopLimitStopLimitGtd `json:"stop_limit_stop_limit_gtd"`
+	MarketMarketIoc       *MarketMarketIoc       `json:"market_market_ioc,omitempty"`
+	LimitLimitGtc         *LimitLimitGtc         `json:"limit_limit_gtc,omitempty"`
+	LimitLimitGtd         *LimitLimitGtd         `json:"limit_limit_gtd,omitempty"`
+	StopLimitStopLimitGtc *StopLimitStopLimitGtc `json:"stop_limit_stop_limit_gtc,omitempty"`
+	StopLimitStopLimitGtd *StopLimitStopLimitGtd `json:"stop_limit_stop_limit_gtd,omitempty"`
+	SorLimitIoc           *SorLimitIoc           `json:"sor_limit_ioc,omitempty"`
+	LimitLimitFok         *LimitLimitFok         `json:"limit_limit_fok,omitempty"`
+	TriggerBracketGtc     *TriggerBracketGtc     `json:"trigger_bracket_gtc,omitempty"`
+	TriggerBracketGtd     *TriggerBracketGtd     `json:"trigger_bracket_gtd,omitempty"`
+	TwapLimitGtd          *TwapLimitGtd          `json:"twap_limit_gtd,omitempty"`
 }
 
 type MarketMarketIoc struct {
-	QuoteSize string `json:"quote_size"`
-	BaseSize  string `json:"base_size"`
+	QuoteSize Decimal `json:"quote_size,omitempty"`
+	BaseSize  Decimal `json:"base_size,omitempty"`
 }
 
 type LimitLimitGtc struct {
-	BaseSize   string `json:"base_size"`
-	LimitPrice string `json:"limit_price"`
-	PostOnly   bool   `json:"post_only"`
+	BaseSize   Decimal `json:"base_size"`
+	LimitPrice Decimal `json:"limit_price"`
+	PostOnly   bool    `json:"post_only"`
 }
 
 type LimitLimitGtd struct {
-	BaseSize   string    `json:"base_size"`
-	LimitPrice string    `json:"limit_price"`
+	BaseSize   Decimal   `json:"base_size"`
+	LimitPrice Decimal   `json:"limit_price"`
 	EndTime    time.Time `json:"end_time"`
 	PostOnly   bool      `json:"post_only"`
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase may send an empty end time.
+func (l *LimitLimitGtd) UnmarshalJSON(data []byte) error {
+	type alias LimitLimitGtd
+	aux := struct {
+		EndTime flexTime `json:"end_time"`
+		*alias
+	}{alias: (*alias)(l)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	l.EndTime = time.Time(aux.EndTime)
+	return nil
+}
+
 type StopLimitStopLimitGtc struct {
-	BaseSize      string `json:"base_size"`
-	LimitPrice    string `json:"limit_price"`
-	StopPrice     string `json:"stop_price"`
-	StopDirection string `json:"stop_direction"`
+	BaseSize      Decimal       `json:"base_size"`
+	LimitPrice    Decimal       `json:"limit_price"`
+	StopPrice     Decimal       `json:"stop_price"`
+	StopDirection StopDirection `json:"stop_direction"`
 }
 
 type StopLimitStopLimitGtd struct {
-	BaseSize      float64   `json:"base_size"`
-	LimitPrice    string    `json:"limit_price"`
-	StopPrice     string    `json:"stop_price"`
-	EndTime       time.Time `json:"end_time"`
-	StopDirection string    `json:"stop_direction"`
+	BaseSize      Decimal       `json:"base_size"`
+	LimitPrice    Decimal       `json:"limit_price"`
+	StopPrice     Decimal       `json:"stop_price"`
+	EndTime       time.Time     `json:"end_time"`
+	StopDirection StopDirection `json:"stop_direction"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase may send an empty end time.
+func (s *StopLimitStopLimitGtd) UnmarshalJSON(data []byte) error {
+	type alias StopLimitStopLimitGtd
+	aux := struct {
+		EndTime flexTime `json:"end_time"`
+		*alias
+	}{alias: (*alias)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.EndTime = time.Time(aux.EndTime)
+	return nil
+}
+
+// SorLimitIoc is an immediate-or-cancel limit order routed by the smart order router.
+type SorLimitIoc struct {
+	QuoteSize  Decimal `json:"quote_size,omitempty"`
+	BaseSize   Decimal `json:"base_size,omitempty"`
+	LimitPrice Decimal `json:"limit_price"`
+}
+
+// LimitLimitFok is a fill-or-kill limit order: it is filled completely right away or cancelled.
+type LimitLimitFok struct {
+	QuoteSize  Decimal `json:"quote_size,omitempty"`
+	BaseSize   Decimal `json:"base_size,omitempty"`
+	LimitPrice Decimal `json:"limit_price"`
+}
+
+// TriggerBracketGtc is a good-till-cancelled bracket order: a take profit limit order at LimitPrice combined with a stop loss that triggers at StopTriggerPrice.
+type TriggerBracketGtc struct {
+	BaseSize         Decimal `json:"base_size"`
+	LimitPrice       Decimal `json:"limit_price"`
+	StopTriggerPrice Decimal `json:"stop_trigger_price"`
+}
+
+// TriggerBracketGtd is a bracket order that expires at EndTime.
+type TriggerBracketGtd struct {
+	BaseSize         Decimal   `json:"base_size"`
+	LimitPrice       Decimal   `json:"limit_price"`
+	StopTriggerPrice Decimal   `json:"stop_trigger_price"`
+	EndTime          time.Time `json:"end_time"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase may send an empty end time.
+func (t *TriggerBracketGtd) UnmarshalJSON(data []byte) error {
+	type alias TriggerBracketGtd
+	aux := struct {
+		EndTime flexTime `json:"end_time"`
+		*alias
+	}{alias: (*alias)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.EndTime = time.Time(aux.EndTime)
+	return nil
+}
+
+// TwapLimitGtd is a time-weighted average price order that is split into buckets executed evenly between StartTime and EndTime.
+type TwapLimitGtd struct {
+	QuoteSize      Decimal   `json:"quote_size,omitempty"`
+	BaseSize       Decimal   `json:"base_size,omitempty"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	LimitPrice     Decimal   `json:"limit_price"`
+	NumberBuckets  string    `json:"number_buckets,omitempty"`
+	BucketSize     Decimal   `json:"bucket_size,omitempty"`
+	BucketDuration string    `json:"bucket_duration,omitempty"` // e.g. "300s"
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase may send an empty start or end time.
+func (t *TwapLimitGtd) UnmarshalJSON(data []byte) error {
+	type alias TwapLimitGtd
+	aux := struct {
+		StartTime flexTime `json:"start_time"`
+		EndTime   flexTime `json:"end_time"`
+		*alias
+	}{alias: (*alias)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.StartTime = time.Time(aux.StartTime)
+	t.EndTime = time.Time(aux.EndTime)
+	return nil
 }
 
 // GetOrder get a single order by order ID.
 func (c *ApiClient) GetOrder(orderId string) (Order, error) {
+	return c.GetOrderWithContext(context.Background(), orderId)
+}
+
+// GetOrderWithContext is like GetOrder but binds the request to the given context.
+func (c *ApiClient) GetOrderWithContext(ctx context.Context, orderId string) (Order, error) {
 	u := c.makeV3Url(fmt.Sprintf("/brokerage/orders/historical/%s", orderId))
 
 	var data GetOrderData
-	if res, err := c.get(u, &data); err != nil {
-		return data.Order, newResponseError(res)
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Order, err
 	}
 	return data.Order, nil
 }
@@ -350,29 +522,194 @@ type CreateOrderRequest struct {
 	// OrderType string Possible values: [UNKNOWN_ORDER_SIDE, BUY, SELL]
 	Side               OrderSide          `json:"side"`
 	OrderConfiguration OrderConfiguration `json:"order_configuration"`
+	// PreviewId string Optional. ID of a preview returned by PreviewOrder, which links the order to the preview.
+	PreviewId string `json:"preview_id,omitempty"`
+	// RetailPortfolioId string Optional. The portfolio to place the order in. Defaults to the default portfolio.
+	RetailPortfolioId string `json:"retail_portfolio_id,omitempty"`
 }
 
 func (req CreateOrderRequest) ToJson() ([]byte, error) {
 	return json.Marshal(req)
 }
 
-// CreateOrder create an order with a specified product_id (asset-pair), side (buy/sell), etc.
+// CreateOrder create an order with a specified product_id (asset-pair), side (buy/sell), etc. The request is only retried on failure when a ClientOrderID is set. A rejected order returns the response data together with a ResponseError, e.g. one that matches IsInsufficientFunds.
 func (c *ApiClient) CreateOrder(req CreateOrderRequest) (CreateOrderData, error) {
+	return c.CreateOrderWithContext(context.Background(), req)
+}
+
+// CreateOrderWithContext is like CreateOrder but binds the request to the given context.
+func (c *ApiClient) CreateOrderWithContext(ctx context.Context, req CreateOrderRequest) (CreateOrderData, error) {
 	var data CreateOrderData
 
 	u := c.makeV3Url("/brokerage/orders")
 
+	// Coinbase de-duplicates orders by client order id, so only then is a retried request unable to place a second order
+	if req.ClientOrderID != "" {
+		ctx = withRetrySafe(ctx)
+	}
+
+	if c.previewOrders {
+		preview, err := c.PreviewOrderWithContext(ctx, req)
+		if err != nil {
+			return data, err
+		}
+		if preview.HasIssues() {
+			return data, PreviewError{Preview: preview}
+		}
+		req.PreviewId = preview.PreviewId
+	}
+
 	body, err := req.ToJson()
 	if err != nil {
 		return data, err
 	}
 
-	if res, err := c.post(u, body, &data); err != nil {
-		return data, newResponseError(res)
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data, err
+	}
+	if !data.Success {
+		return data, newOrderFailureError(u, data)
 	}
 	return data, nil
 }
 
+// newOrderFailureError builds a ResponseError for an order that Coinbase accepted the request for but rejected, which is reported with a 200 status.
+func newOrderFailureError(rawUrl string, data CreateOrderData) error {
+	e := ResponseError{
+		StatusCode: http.StatusOK,
+		Method:     http.MethodPost,
+		Code:       data.ErrorResponse.Error,
+		Message:    data.ErrorResponse.Message,
+	}
+	if p, err := url.Parse(rawUrl); err == nil {
+		e.Path = p.Path
+	}
+	if e.Code == "" {
+		e.Code = data.FailureReason
+	}
+	for _, msg := range []string{data.ErrorResponse.ErrorDetails, data.ErrorResponse.NewOrderFailureReason, data.ErrorResponse.PreviewFailureReason} {
+		if e.Message == "" {
+			e.Message = msg
+		}
+	}
+	return e
+}
+
+// ClosePosition close an open futures position with a market order. An empty size closes the whole position. A client order id is generated so the request is safe to retry.
+func (c *ApiClient) ClosePosition(productId string, size Decimal) (CreateOrderData, error) {
+	return c.ClosePositionWithContext(context.Background(), productId, size)
+}
+
+// ClosePositionWithContext is like ClosePosition but binds the request to the given context.
+func (c *ApiClient) ClosePositionWithContext(ctx context.Context, productId string, size Decimal) (CreateOrderData, error) {
+	var data CreateOrderData
+
+	u := c.makeV3Url("/brokerage/orders/close_position")
+
+	clientOrderId, err := newClientOrderId()
+	if err != nil {
+		return data, err
+	}
+	ctx = withRetrySafe(ctx)
+
+	body, err := json.Marshal(struct {
+		ClientOrderID string  `json:"client_order_id"`
+		ProductID     string  `json:"product_id"`
+		Size          Decimal `json:"size,omitempty"`
+	}{clientOrderId, productId, size})
+	if err != nil {
+		return data, err
+	}
+
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data, err
+	}
+	if !data.Success {
+		return data, newOrderFailureError(u, data)
+	}
+	return data, nil
+}
+
+// SetPreviewOrders makes CreateOrder preview every order first. If the preview reports any error or warning, such as insufficient funds or high slippage, the order is not placed and a PreviewError is returned.
+func (c *ApiClient) SetPreviewOrders(enabled bool) {
+	c.previewOrders = enabled
+}
+
+// PreviewOrder simulates an order without placing it, returning the order total, commission, slippage and any reasons the order would fail.
+func (c *ApiClient) PreviewOrder(req CreateOrderRequest) (PreviewOrderData, error) {
+	return c.PreviewOrderWithContext(context.Background(), req)
+}
+
+// PreviewOrderWithContext is like PreviewOrder but binds the request to the given context.
+func (c *ApiClient) PreviewOrderWithContext(ctx context.Context, req CreateOrderRequest) (PreviewOrderData, error) {
+	var data PreviewOrderData
+
+	u := c.makeV3Url("/brokerage/orders/preview")
+
+	// previews have no side effect
+	ctx = withRetrySafe(ctx)
+
+	body, err := json.Marshal(struct {
+		ProductID          string             `json:"product_id"`
+		Side               OrderSide          `json:"side"`
+		OrderConfiguration OrderConfiguration `json:"order_configuration"`
+		RetailPortfolioId  string             `json:"retail_portfolio_id,omitempty"`
+	}{req.ProductID, req.Side, req.OrderConfiguration, req.RetailPortfolioId})
+	if err != nil {
+		return data, err
+	}
+
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type PreviewOrderData struct {
+	PreviewId          string   `json:"preview_id"`
+	OrderTotal         Decimal  `json:"order_total"`
+	CommissionTotal    Decimal  `json:"commission_total"`
+	Errs               []string `json:"errs"`
+	Warning            []string `json:"warning"`
+	QuoteSize          Decimal  `json:"quote_size"`
+	BaseSize           Decimal  `json:"base_size"`
+	BestBid            Decimal  `json:"best_bid"`
+	BestAsk            Decimal  `json:"best_ask"`
+	IsMax              bool     `json:"is_max"`
+	Slippage           Decimal  `json:"slippage"`
+	AverageFilledPrice Decimal  `json:"average_filled_price"`
+	OrderMarginTotal   Decimal  `json:"order_margin_total"`
+	Leverage           Decimal  `json:"leverage"`
+}
+
+// HasIssues reports whether the preview returned any error or warning.
+func (d PreviewOrderData) HasIssues() bool {
+	return len(d.Errs) > 0 || len(d.Warning) > 0
+}
+
+// PreviewError is returned by CreateOrder when previews are enabled with SetPreviewOrders and the preview reported an error or a warning.
+type PreviewError struct {
+	Preview PreviewOrderData
+}
+
+// Error implements the error interface.
+func (e PreviewError) Error() string {
+	return fmt.Sprintf("order preview failed: %s", strings.Join(append(append([]string{}, e.Preview.Errs...), e.Preview.Warning...), ", "))
+}
+
+// Is reports whether the preview failed for lack of funds, so IsInsufficientFunds works on previews as well.
+func (e PreviewError) Is(target error) bool {
+	if target != ErrInsufficientFunds {
+		return false
+	}
+	for _, reason := range e.Preview.Errs {
+		if strings.Contains(reason, "INSUFFICIENT_FUND") {
+			return true
+		}
+	}
+	return false
+}
+
 type CreateOrderData struct {
 	Success            bool                       `json:"success"`
 	FailureReason      string                     `json:"failure_reason"`
@@ -399,10 +736,18 @@ type CreateOrderSuccessResponse struct {
 
 // CancelOrders initiate cancel requests for one or more orders.
 func (c *ApiClient) CancelOrders(orderIds []string) (CancelOrdersData, error) {
+	return c.CancelOrdersWithContext(context.Background(), orderIds)
+}
+
+// CancelOrdersWithContext is like CancelOrders but binds the request to the given context.
+func (c *ApiClient) CancelOrdersWithContext(ctx context.Context, orderIds []string) (CancelOrdersData, error) {
 	var data CancelOrdersData
 
 	u := c.makeV3Url("/brokerage/orders/batch_cancel")
 
+	// cancelling an order twice has no further effect
+	ctx = withRetrySafe(ctx)
+
 	ords := struct {
 		OrderIds []string `json:"order_ids"`
 	}{OrderIds: orderIds}
@@ -412,8 +757,8 @@ func (c *ApiClient) CancelOrders(orderIds []string) (CancelOrdersData, error) {
 		return data, err
 	}
 
-	if res, err := c.post(u, body, &data); err != nil {
-		return data, newResponseError(res)
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data, err
 	}
 	return data, nil
 }
@@ -454,9 +799,9 @@ type EditOrderRequest struct {
 	// OrderId ID of order to edit.
 	OrderId string `json:"order_id"`
 	// Price New price of order. Required if order type is limit or stop-limit.
-	Price string `json:"price"`
+	Price Decimal `json:"price"`
 	// Size New size of order. Required if order type is limit or stop-limit.
-	Size string `json:"size"`
+	Size Decimal `json:"size"`
 }
 
 func (req EditOrderRequest) ToJson() ([]byte, error) {
@@ -465,6 +810,11 @@ func (req EditOrderRequest) ToJson() ([]byte, error) {
 
 // EditOrder edit an order with a specified new size, or new price. Only limit order types, with time in force type of good-till-cancelled can be edited.
 func (c *ApiClient) EditOrder(req EditOrderRequest) (EditOrderData, error) {
+	return c.EditOrderWithContext(context.Background(), req)
+}
+
+// EditOrderWithContext is like EditOrder but binds the request to the given context.
+func (c *ApiClient) EditOrderWithContext(ctx context.Context, req EditOrderRequest) (EditOrderData, error) {
 	var data EditOrderData
 
 	u := c.makeV3Url("/brokerage/orders/edit")
@@ -474,8 +824,8 @@ func (c *ApiClient) EditOrder(req EditOrderRequest) (EditOrderData, error) {
 		return data, err
 	}
 
-	if res, err := c.post(u, body, &data); err != nil {
-		return data, newResponseError(res)
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data, err
 	}
 	return data, nil
 }
@@ -492,6 +842,11 @@ type EditOrderErrors struct {
 
 // EditOrderPreview edit an order with a specified new size, or new price. Only limit order types, with time in force type of good-till-cancelled can be edited.
 func (c *ApiClient) EditOrderPreview(req EditOrderRequest) (EditOrderPreviewData, error) {
+	return c.EditOrderPreviewWithContext(context.Background(), req)
+}
+
+// EditOrderPreviewWithContext is like EditOrderPreview but binds the request to the given context.
+func (c *ApiClient) EditOrderPreviewWithContext(ctx context.Context, req EditOrderRequest) (EditOrderPreviewData, error) {
 	var data EditOrderPreviewData
 
 	u := c.makeV3Url("/brokerage/orders/edit_preview")
@@ -501,20 +856,20 @@ func (c *ApiClient) EditOrderPreview(req EditOrderRequest) (EditOrderPreviewData
 		return data, err
 	}
 
-	if res, err := c.post(u, body, &data); err != nil {
-		return data, newResponseError(res)
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data, err
 	}
 	return data, nil
 }
 
 type EditOrderPreviewData struct {
 	Errors             EditOrderErrors `json:"errors"`
-	Slippage           string          `json:"slippage"`
-	OrderTotal         string          `json:"order_total"`
-	CommissionTotal    string          `json:"commission_total"`
-	QuoteSize          string          `json:"quote_size"`
-	BaseSize           string          `json:"base_size"`
-	BestBid            string          `json:"best_bid"`
-	BestAsk            string          `json:"best_ask"`
-	AverageFilledPrice string          `json:"average_filled_price"`
+	Slippage           Decimal         `json:"slippage"`
+	OrderTotal         Decimal         `json:"order_total"`
+	CommissionTotal    Decimal         `json:"commission_total"`
+	QuoteSize          Decimal         `json:"quote_size"`
+	BaseSize           Decimal         `json:"base_size"`
+	BestBid            Decimal         `json:"best_bid"`
+	BestAsk            Decimal         `json:"best_ask"`
+	AverageFilledPrice Decimal         `json:"average_filled_price"`
 }
@@ -1,10 +1,14 @@
 package coinbasev3
 
 import (
+	"errors"
 	"fmt"
 	"github.com/jarcoal/httpmock"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestApiClient_GetListFills_Empty(t *testing.T) {
@@ -142,7 +146,7 @@ func TestApiClient_GetListOrders_WithOrderDataArray(t *testing.T) {
 
 	httpmock.ActivateNonDefault(api.client.GetClient())
 	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/batch", func(request *http.Request) (*http.Response, error) {
-		respBody := `{"orders":[{"order_id":"0000-000000-000000","product_id":"BTC-USD","user_id":"2222-000000-000000","order_configuration":{"market_market_ioc":{"quote_size":"10.00","base_size":"0.001"},"limit_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","post_only":false},"limit_limit_gtd":{"base_size":"0.001","limit_price":"10000.00","end_time":"2021-05-31T09:59:59Z","post_only":false},"stop_limit_stop_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","stop_price":"20000.00","stop_direction":"UNKNOWN_STOP_DIRECTION"},"stop_limit_stop_limit_gtd":{"base_size":0.001,"limit_price":"10000.00","stop_price":"20000.00","end_time":"2021-05-31T09:59:59Z","stop_direction":"UNKNOWN_STOP_DIRECTION"}},"side":"UNKNOWN_ORDER_SIDE","client_order_id":"11111-000000-000000","status":"OPEN","time_in_force":"UNKNOWN_TIME_IN_FORCE","created_time":"2021-05-31T09:59:59Z","completion_percentage":"50","filled_size":"0.001","average_filled_price":"50","fee":"string","number_of_fills":"2","filled_value":"10000","pending_cancel":true,"size_in_quote":false,"total_fees":"5.00","size_inclusive_of_fees":false,"total_value_after_fees":"string","trigger_status":"UNKNOWN_TRIGGER_STATUS","order_type":"UNKNOWN_ORDER_TYPE","reject_reason":"REJECT_REASON_UNSPECIFIED","settled":"boolean","product_type":"SPOT","reject_message":"string","cancel_message":"string","order_placement_source":"RETAIL_ADVANCED","outstanding_hold_amount":"string","is_liquidation":"boolean","last_fill_time":"string","edit_history":[{"price":"string","size":"string","replace_accept_timestamp":"string"}]}],"sequence":"string","has_next":true,"cursor":"789100"}`
+		respBody := `{"orders":[{"order_id":"0000-000000-000000","product_id":"BTC-USD","user_id":"2222-000000-000000","order_configuration":{"market_market_ioc":{"quote_size":"10.00","base_size":"0.001"},"limit_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","post_only":false},"limit_limit_gtd":{"base_size":"0.001","limit_price":"10000.00","end_time":"2021-05-31T09:59:59Z","post_only":false},"stop_limit_stop_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","stop_price":"20000.00","stop_direction":"UNKNOWN_STOP_DIRECTION"},"stop_limit_stop_limit_gtd":{"base_size":0.001,"limit_price":"10000.00","stop_price":"20000.00","end_time":"2021-05-31T09:59:59Z","stop_direction":"UNKNOWN_STOP_DIRECTION"}},"side":"UNKNOWN_ORDER_SIDE","client_order_id":"11111-000000-000000","status":"OPEN","time_in_force":"UNKNOWN_TIME_IN_FORCE","created_time":"2021-05-31T09:59:59Z","completion_percentage":"50","filled_size":"0.001","average_filled_price":"50","fee":"string","number_of_fills":"2","filled_value":"10000","pending_cancel":true,"size_in_quote":false,"total_fees":"5.00","size_inclusive_of_fees":false,"total_value_after_fees":"string","trigger_status":"UNKNOWN_TRIGGER_STATUS","order_type":"UNKNOWN_ORDER_TYPE","reject_reason":"REJECT_REASON_UNSPECIFIED","settled":"boolean","product_type":"SPOT","reject_message":"string","cancel_message":"string","order_placement_source":"RETAIL_ADVANCED","outstanding_hold_amount":"string","is_liquidation":"boolean","last_fill_time":"2023-11-28T17:02:18.95103Z","edit_history":[{"price":"string","size":"string","replace_accept_timestamp":"2023-11-28T16:59:02.125Z"}]}],"sequence":"string","has_next":true,"cursor":"789100"}`
 		resp := httpmock.NewStringResponse(http.StatusOK, respBody)
 		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
 		return resp, nil
@@ -163,7 +167,7 @@ func TestApiClient_GetListOrders_WithOrderData_Single(t *testing.T) {
 
 	httpmock.ActivateNonDefault(api.client.GetClient())
 	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/batch", func(request *http.Request) (*http.Response, error) {
-		respBody := `{"orders":{"order_id":"0000-000000-000000","product_id":"BTC-USD","user_id":"2222-000000-000000","order_configuration":{"market_market_ioc":{"quote_size":"10.00","base_size":"0.001"},"limit_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","post_only":false},"limit_limit_gtd":{"base_size":"0.001","limit_price":"10000.00","end_time":"2021-05-31T09:59:59Z","post_only":false},"stop_limit_stop_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","stop_price":"20000.00","stop_direction":"UNKNOWN_STOP_DIRECTION"},"stop_limit_stop_limit_gtd":{"base_size":0.001,"limit_price":"10000.00","stop_price":"20000.00","end_time":"2021-05-31T09:59:59Z","stop_direction":"UNKNOWN_STOP_DIRECTION"}},"side":"UNKNOWN_ORDER_SIDE","client_order_id":"11111-000000-000000","status":"OPEN","time_in_force":"UNKNOWN_TIME_IN_FORCE","created_time":"2021-05-31T09:59:59Z","completion_percentage":"50","filled_size":"0.001","average_filled_price":"50","fee":"string","number_of_fills":"2","filled_value":"10000","pending_cancel":true,"size_in_quote":false,"total_fees":"5.00","size_inclusive_of_fees":false,"total_value_after_fees":"string","trigger_status":"UNKNOWN_TRIGGER_STATUS","order_type":"UNKNOWN_ORDER_TYPE","reject_reason":"REJECT_REASON_UNSPECIFIED","settled":"boolean","product_type":"SPOT","reject_message":"string","cancel_message":"string","order_placement_source":"RETAIL_ADVANCED","outstanding_hold_amount":"string","is_liquidation":"boolean","last_fill_time":"string","edit_history":[{"price":"string","size":"string","replace_accept_timestamp":"string"}]},"sequence":"string","has_next":true,"cursor":"789100"}`
+		respBody := `{"orders":{"order_id":"0000-000000-000000","product_id":"BTC-USD","user_id":"2222-000000-000000","order_configuration":{"market_market_ioc":{"quote_size":"10.00","base_size":"0.001"},"limit_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","post_only":false},"limit_limit_gtd":{"base_size":"0.001","limit_price":"10000.00","end_time":"2021-05-31T09:59:59Z","post_only":false},"stop_limit_stop_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","stop_price":"20000.00","stop_direction":"UNKNOWN_STOP_DIRECTION"},"stop_limit_stop_limit_gtd":{"base_size":0.001,"limit_price":"10000.00","stop_price":"20000.00","end_time":"2021-05-31T09:59:59Z","stop_direction":"UNKNOWN_STOP_DIRECTION"}},"side":"UNKNOWN_ORDER_SIDE","client_order_id":"11111-000000-000000","status":"OPEN","time_in_force":"UNKNOWN_TIME_IN_FORCE","created_time":"2021-05-31T09:59:59Z","completion_percentage":"50","filled_size":"0.001","average_filled_price":"50","fee":"string","number_of_fills":"2","filled_value":"10000","pending_cancel":true,"size_in_quote":false,"total_fees":"5.00","size_inclusive_of_fees":false,"total_value_after_fees":"string","trigger_status":"UNKNOWN_TRIGGER_STATUS","order_type":"UNKNOWN_ORDER_TYPE","reject_reason":"REJECT_REASON_UNSPECIFIED","settled":"boolean","product_type":"SPOT","reject_message":"string","cancel_message":"string","order_placement_source":"RETAIL_ADVANCED","outstanding_hold_amount":"string","is_liquidation":"boolean","last_fill_time":"2023-11-28T17:02:18.95103Z","edit_history":[{"price":"string","size":"string","replace_accept_timestamp":"2023-11-28T16:59:02.125Z"}]},"sequence":"string","has_next":true,"cursor":"789100"}`
 		resp := httpmock.NewStringResponse(http.StatusOK, respBody)
 		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
 		return resp, nil
@@ -184,7 +188,7 @@ func TestApiClient_GetOrder(t *testing.T) {
 
 	httpmock.ActivateNonDefault(api.client.GetClient())
 	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/0000-000000-000000", func(request *http.Request) (*http.Response, error) {
-		respBody := `{"order":{"order_id":"0000-000000-000000","product_id":"BTC-USD","user_id":"2222-000000-000000","order_configuration":{"market_market_ioc":{"quote_size":"10.00","base_size":"0.001"},"limit_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","post_only":false},"limit_limit_gtd":{"base_size":"0.001","limit_price":"10000.00","end_time":"2021-05-31T09:59:59Z","post_only":false},"stop_limit_stop_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","stop_price":"20000.00","stop_direction":"UNKNOWN_STOP_DIRECTION"},"stop_limit_stop_limit_gtd":{"base_size":0.001,"limit_price":"10000.00","stop_price":"20000.00","end_time":"2021-05-31T09:59:59Z","stop_direction":"UNKNOWN_STOP_DIRECTION"}},"side":"UNKNOWN_ORDER_SIDE","client_order_id":"11111-000000-000000","status":"OPEN","time_in_force":"UNKNOWN_TIME_IN_FORCE","created_time":"2021-05-31T09:59:59Z","completion_percentage":"50","filled_size":"0.001","average_filled_price":"50","fee":"string","number_of_fills":"2","filled_value":"10000","pending_cancel":true,"size_in_quote":false,"total_fees":"5.00","size_inclusive_of_fees":false,"total_value_after_fees":"string","trigger_status":"UNKNOWN_TRIGGER_STATUS","order_type":"UNKNOWN_ORDER_TYPE","reject_reason":"REJECT_REASON_UNSPECIFIED","settled":"boolean","product_type":"SPOT","reject_message":"string","cancel_message":"string","order_placement_source":"RETAIL_ADVANCED","outstanding_hold_amount":"string","is_liquidation":"boolean","last_fill_time":"string","edit_history":[{"price":"string","size":"string","replace_accept_timestamp":"string"}]}}`
+		respBody := `{"order":{"order_id":"0000-000000-000000","product_id":"BTC-USD","user_id":"2222-000000-000000","order_configuration":{"market_market_ioc":{"quote_size":"10.00","base_size":"0.001"},"limit_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","post_only":false},"limit_limit_gtd":{"base_size":"0.001","limit_price":"10000.00","end_time":"2021-05-31T09:59:59Z","post_only":false},"stop_limit_stop_limit_gtc":{"base_size":"0.001","limit_price":"10000.00","stop_price":"20000.00","stop_direction":"UNKNOWN_STOP_DIRECTION"},"stop_limit_stop_limit_gtd":{"base_size":0.001,"limit_price":"10000.00","stop_price":"20000.00","end_time":"2021-05-31T09:59:59Z","stop_direction":"UNKNOWN_STOP_DIRECTION"}},"side":"UNKNOWN_ORDER_SIDE","client_order_id":"11111-000000-000000","status":"OPEN","time_in_force":"UNKNOWN_TIME_IN_FORCE","created_time":"2021-05-31T09:59:59Z","completion_percentage":"50","filled_size":"0.001","average_filled_price":"50","fee":"string","number_of_fills":"2","filled_value":"10000","pending_cancel":true,"size_in_quote":false,"total_fees":"5.00","size_inclusive_of_fees":false,"total_value_after_fees":"string","trigger_status":"UNKNOWN_TRIGGER_STATUS","order_type":"UNKNOWN_ORDER_TYPE","reject_reason":"REJECT_REASON_UNSPECIFIED","settled":"boolean","product_type":"SPOT","reject_message":"string","cancel_message":"string","order_placement_source":"RETAIL_ADVANCED","outstanding_hold_amount":"string","is_liquidation":"boolean","last_fill_time":"2023-11-28T17:02:18.95103Z","edit_history":[{"price":"string","size":"string","replace_accept_timestamp":"2023-11-28T16:59:02.125Z"}]}}`
 		resp := httpmock.NewStringResponse(http.StatusOK, respBody)
 		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
 		return resp, nil
@@ -198,6 +202,53 @@ func TestApiClient_GetOrder(t *testing.T) {
 	if data.OrderId != "0000-000000-000000" {
 		t.Fatalf("Expected OrderId to be 0000-000000-000000, got %s", data.OrderId)
 	}
+	if data.NumberOfFills != 2 {
+		t.Errorf("Expected 2 fills, got %d", data.NumberOfFills)
+	}
+	if expected := time.Date(2023, 11, 28, 17, 2, 18, 951030000, time.UTC); !data.LastFillTime.Equal(expected) {
+		t.Errorf("Expected last fill time %s, got %s", expected, data.LastFillTime)
+	}
+	if len(data.EditHistory) != 1 || data.EditHistory[0].ReplaceAcceptTimestamp.IsZero() {
+		t.Errorf("Expected a parsed edit history timestamp, got %+v", data.EditHistory)
+	}
+}
+
+func TestApiClient_GetOrder_AdvancedOrderTypes(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/1111", func(request *http.Request) (*http.Response, error) {
+		respBody := `{"order":{"order_id":"1111","product_id":"BTC-USD","order_configuration":{"twap_limit_gtd":{"base_size":"0.1","start_time":"2023-11-28T16:00:00Z","end_time":"2023-11-28T18:00:00Z","limit_price":"31000","number_buckets":"24","bucket_size":"0.00416666","bucket_duration":"300s"}},"side":"BUY","order_type":"TWAP"}}`
+		resp := httpmock.NewStringResponse(http.StatusOK, respBody)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/2222", func(request *http.Request) (*http.Response, error) {
+		respBody := `{"order":{"order_id":"2222","product_id":"BTC-USD","order_configuration":{"trigger_bracket_gtc":{"base_size":"0.1","limit_price":"35000","stop_trigger_price":"28000"}},"side":"SELL","order_type":"BRACKET"}}`
+		resp := httpmock.NewStringResponse(http.StatusOK, respBody)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	twap, err := api.GetOrder("1111")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	cfg := twap.OrderConfiguration.TwapLimitGtd
+	if cfg == nil || cfg.BucketDuration != "300s" || cfg.BucketSize != "0.00416666" || cfg.EndTime.Sub(cfg.StartTime) != 2*time.Hour {
+		t.Errorf("Unexpected twap configuration %+v", cfg)
+	}
+	if twap.OrderConfiguration.MarketMarketIoc != nil {
+		t.Errorf("Expected only the twap configuration to be set")
+	}
+
+	bracket, err := api.GetOrder("2222")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if bracket.OrderType != string(OrderTypeBracket) || bracket.OrderConfiguration.TriggerBracketGtc.StopTriggerPrice != "28000" {
+		t.Errorf("Unexpected bracket order %+v", bracket)
+	}
 }
 
 func TestApiClient_CreateOrder(t *testing.T) {
@@ -316,3 +367,117 @@ func TestApiClient_EditOrderPreview(t *testing.T) {
 		t.Fatalf("Expected Slippage to be string, got %s", data.Slippage)
 	}
 }
+
+func TestListOrdersQuery_BuildQueryString(t *testing.T) {
+	q := ListOrdersQuery{ProductId: "BTC-USD", OrderStatus: []string{"OPEN"}, Limit: 10}
+	if q.BuildQueryString() != "?product_id=BTC-USD&order_status=OPEN&limit=10" {
+		t.Errorf("Expected ?product_id=BTC-USD&order_status=OPEN&limit=10, got %s", q.BuildQueryString())
+	}
+
+	empty := ListOrdersQuery{}
+	if empty.BuildQueryString() != "" {
+		t.Errorf("Expected empty query string, got %s", empty.BuildQueryString())
+	}
+}
+
+func TestApiClient_PreviewOrder(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var body string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders/preview", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		body = string(b)
+		respBody := `{"order_total":"100.6","commission_total":"0.6","errs":[],"warning":[],"quote_size":"100","base_size":"0.0025","best_bid":"39999.99","best_ask":"40000","is_max":false,"slippage":"0.0001","preview_id":"p-1"}`
+		resp := httpmock.NewStringResponse(http.StatusOK, respBody)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	req, _ := MarketBuy("BTC-USD", "100").ClientOrderId("0000").Build()
+	data, err := api.PreviewOrder(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if data.OrderTotal != "100.6" || data.CommissionTotal != "0.6" || data.BestAsk != "40000" || data.PreviewId != "p-1" {
+		t.Errorf("Unexpected preview %+v", data)
+	}
+	if data.HasIssues() {
+		t.Errorf("Expected no issues")
+	}
+	if body != `{"product_id":"BTC-USD","side":"BUY","order_configuration":{"market_market_ioc":{"quote_size":"100"}}}` {
+		t.Errorf("Unexpected request body %s", body)
+	}
+}
+
+func TestApiClient_CreateOrder_Preview(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+	api.SetPreviewOrders(true)
+
+	previewBody := `{"order_total":"100.6","errs":[],"warning":[],"preview_id":"p-1"}`
+	var createBody string
+	creates := 0
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders/preview", func(request *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(http.StatusOK, previewBody)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders", func(request *http.Request) (*http.Response, error) {
+		creates++
+		b, _ := io.ReadAll(request.Body)
+		createBody = string(b)
+		resp := httpmock.NewStringResponse(http.StatusOK, `{"success":true,"order_id":"1111"}`)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	req, _ := MarketBuy("BTC-USD", "100").ClientOrderId("0000").Build()
+	if _, err := api.CreateOrder(req); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !strings.Contains(createBody, `"preview_id":"p-1"`) {
+		t.Errorf("Expected the preview id to be sent, got %s", createBody)
+	}
+
+	previewBody = `{"order_total":"100.6","errs":["PREVIEW_INSUFFICIENT_FUND"],"warning":["BIG_ORDER"]}`
+	_, err := api.CreateOrder(req)
+	var previewErr PreviewError
+	if !errors.As(err, &previewErr) {
+		t.Fatalf("Expected a PreviewError, got %v", err)
+	}
+	if !IsInsufficientFunds(err) {
+		t.Errorf("Expected an insufficient funds error")
+	}
+	if err.Error() != "order preview failed: PREVIEW_INSUFFICIENT_FUND, BIG_ORDER" {
+		t.Errorf("Unexpected error message %s", err)
+	}
+	if creates != 1 {
+		t.Errorf("Expected the order not to be placed, got %d creates", creates)
+	}
+}
+
+func TestApiClient_ClosePosition(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var body string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders/close_position", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		body = string(b)
+		resp := httpmock.NewStringResponse(http.StatusOK, `{"success":true,"success_response":{"order_id":"o-1","product_id":"BIT-28JUN24-CDE","side":"SELL"}}`)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	data, err := api.ClosePosition("BIT-28JUN24-CDE", "2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !data.Success || data.SuccessResponse.OrderId != "o-1" {
+		t.Errorf("Unexpected response %+v", data)
+	}
+	if !strings.Contains(body, `"product_id":"BIT-28JUN24-CDE","size":"2"`) || !strings.Contains(body, `"client_order_id":"`) {
+		t.Errorf("Unexpected request body %s", body)
+	}
+}
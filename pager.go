@@ -0,0 +1,200 @@
+package coinbasev3
+
+import (
+	"context"
+	"strconv"
+)
+
+// Page is a single page of results returned by a cursor-based list endpoint.
+type Page[T any] struct {
+	Items   []T
+	Cursor  string
+	HasNext bool
+}
+
+// PageFetcher fetches the page that starts at the given cursor. An empty cursor requests the first page.
+type PageFetcher[T any] func(ctx context.Context, cursor string) (Page[T], error)
+
+// Pager transparently follows the cursors of a list endpoint and yields one item at a time.
+//
+//	pager := client.NewOrdersPager(coinbasev3.ListOrdersQuery{ProductId: "BTC-USD"})
+//	for pager.Next(ctx) {
+//		order := pager.Item()
+//	}
+//	if err := pager.Err(); err != nil {
+//		panic(err)
+//	}
+type Pager[T any] struct {
+	fetch    PageFetcher[T]
+	cursor   string
+	items    []T
+	item     T
+	count    int
+	maxItems int
+	fetched  bool
+	hasNext  bool
+	stopped  bool
+	err      error
+}
+
+// NewPager creates a pager that starts at the given cursor.
+func NewPager[T any](fetch PageFetcher[T], cursor string) *Pager[T] {
+	return &Pager[T]{
+		fetch:  fetch,
+		cursor: cursor,
+	}
+}
+
+// SetMaxItems caps the total number of items the pager yields. Zero or a negative value means no cap.
+func (p *Pager[T]) SetMaxItems(n int) *Pager[T] {
+	p.maxItems = n
+	return p
+}
+
+// Next advances to the next item, fetching the next page when the current one is exhausted. It returns false when there are no more items, the max items cap is reached, Stop was called, the context is done or a request fails.
+func (p *Pager[T]) Next(ctx context.Context) bool {
+	if p.stopped || p.err != nil {
+		return false
+	}
+	if p.maxItems > 0 && p.count >= p.maxItems {
+		return false
+	}
+
+	for len(p.items) == 0 {
+		if p.fetched && !p.hasNext {
+			return false
+		}
+		if err := ctx.Err(); err != nil {
+			p.err = err
+			return false
+		}
+
+		page, err := p.fetch(ctx, p.cursor)
+		if err != nil {
+			p.err = err
+			return false
+		}
+
+		p.fetched = true
+		p.items = page.Items
+		// guard against endpoints that report more pages without handing out a new cursor
+		p.hasNext = page.HasNext && page.Cursor != "" && page.Cursor != p.cursor
+		p.cursor = page.Cursor
+	}
+
+	p.item = p.items[0]
+	p.items = p.items[1:]
+	p.count++
+	return true
+}
+
+// Item returns the current item. Only valid after Next returned true.
+func (p *Pager[T]) Item() T {
+	return p.item
+}
+
+// Err returns the first error encountered while paging, including context cancellation.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// Cursor returns the cursor of the next page, which can be used to resume paging later.
+func (p *Pager[T]) Cursor() string {
+	return p.cursor
+}
+
+// Stop ends the iteration early. Subsequent calls to Next return false.
+func (p *Pager[T]) Stop() {
+	p.stopped = true
+}
+
+// All collects every remaining item into a slice. Intended for small result sets; use SetMaxItems to bound the result.
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for p.Next(ctx) {
+		all = append(all, p.Item())
+	}
+	return all, p.Err()
+}
+
+// NewAccountsPager creates a pager over ListAccounts. The limit is the page size.
+func (c *ApiClient) NewAccountsPager(limit int) *Pager[Account] {
+	return NewPager(func(ctx context.Context, cursor string) (Page[Account], error) {
+		data, err := c.ListAccountsWithContext(ctx, limit, cursor)
+		if err != nil {
+			return Page[Account]{}, err
+		}
+		return Page[Account]{Items: data.Accounts, Cursor: data.Cursor, HasNext: data.HasNext}, nil
+	}, "")
+}
+
+// NewOrdersPager creates a pager over GetListOrders. The query's Cursor is used as the starting cursor and its Limit as the page size.
+func (c *ApiClient) NewOrdersPager(q ListOrdersQuery) *Pager[Order] {
+	return NewPager(func(ctx context.Context, cursor string) (Page[Order], error) {
+		q.Cursor = cursor
+		data, err := c.GetListOrdersWithContext(ctx, q)
+		if err != nil {
+			return Page[Order]{}, err
+		}
+		return Page[Order]{Items: data.Orders, Cursor: data.Cursor, HasNext: data.HasNext}, nil
+	}, q.Cursor)
+}
+
+// NewFillsPager creates a pager over GetListFills. The query's Cursor is used as the starting cursor and its Limit as the page size. The fills endpoint does not return has_next, so paging continues while a cursor is returned.
+func (c *ApiClient) NewFillsPager(q ListFillsQuery) *Pager[Fill] {
+	return NewPager(func(ctx context.Context, cursor string) (Page[Fill], error) {
+		q.Cursor = cursor
+		data, err := c.GetListFillsWithContext(ctx, q)
+		if err != nil {
+			return Page[Fill]{}, err
+		}
+		return Page[Fill]{Items: data.Fills, Cursor: data.Cursor, HasNext: data.Cursor != ""}, nil
+	}, q.Cursor)
+}
+
+// NewWalletAccountsPager creates a pager over WalletListAccounts. The query's StartingAfter is used as the starting cursor and its Limit as the page size.
+func (c *ApiClient) NewWalletAccountsPager(q WalletPaginationQuery) *Pager[WalletAccount] {
+	return NewPager(func(ctx context.Context, cursor string) (Page[WalletAccount], error) {
+		q.StartingAfter = cursor
+		data, err := c.WalletListAccountsWithContext(ctx, q)
+		if err != nil {
+			return Page[WalletAccount]{}, err
+		}
+		return Page[WalletAccount]{Items: data.Data, Cursor: data.Pagination.NextStartingAfter, HasNext: data.Pagination.HasNext()}, nil
+	}, q.StartingAfter)
+}
+
+// NewWalletTransactionsPager creates a pager over WalletListTransactions. The query's StartingAfter is used as the starting cursor and its Limit as the page size.
+func (c *ApiClient) NewWalletTransactionsPager(accountId string, q WalletPaginationQuery) *Pager[WalletTransaction] {
+	return NewPager(func(ctx context.Context, cursor string) (Page[WalletTransaction], error) {
+		q.StartingAfter = cursor
+		data, err := c.WalletListTransactionsWithContext(ctx, accountId, q)
+		if err != nil {
+			return Page[WalletTransaction]{}, err
+		}
+		return Page[WalletTransaction]{Items: data.Data, Cursor: data.Pagination.NextStartingAfter, HasNext: data.Pagination.HasNext()}, nil
+	}, q.StartingAfter)
+}
+
+// NewProductsPager creates a pager over ListProducts. The products endpoint is offset based, so the query's Offset is used as the starting position and its Limit as the page size. Paging stops once num_products products have been seen or a page comes back empty.
+func (c *ApiClient) NewProductsPager(q ListProductsQuery) *Pager[Product] {
+	return NewPager(func(ctx context.Context, cursor string) (Page[Product], error) {
+		if cursor != "" {
+			offset, err := strconv.ParseInt(cursor, 10, 32)
+			if err != nil {
+				return Page[Product]{}, err
+			}
+			q.Offset = int32(offset)
+		}
+		data, err := c.ListProductsWithContext(ctx, q)
+		if err != nil {
+			return Page[Product]{}, err
+		}
+		next := q.Offset + int32(len(data.Products))
+		return Page[Product]{
+			Items:   data.Products,
+			Cursor:  strconv.FormatInt(int64(next), 10),
+			HasNext: len(data.Products) > 0 && next < data.NumProducts,
+		}, nil
+	}, "")
+}
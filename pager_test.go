@@ -0,0 +1,190 @@
+package coinbasev3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"testing"
+)
+
+func TestPager_FollowsCursors(t *testing.T) {
+	pages := map[string]Page[int]{
+		"":  {Items: []int{1, 2}, Cursor: "a", HasNext: true},
+		"a": {Items: []int{3, 4}, Cursor: "b", HasNext: true},
+		"b": {Items: []int{5}, Cursor: "", HasNext: false},
+	}
+
+	calls := 0
+	p := NewPager(func(ctx context.Context, cursor string) (Page[int], error) {
+		calls++
+		return pages[cursor], nil
+	}, "")
+
+	items, err := p.All(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("Expected 5 items, got %d", len(items))
+	}
+	for i, v := range items {
+		if v != i+1 {
+			t.Errorf("Expected %d, got %d", i+1, v)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 page fetches, got %d", calls)
+	}
+}
+
+func TestPager_MaxItems(t *testing.T) {
+	calls := 0
+	p := NewPager(func(ctx context.Context, cursor string) (Page[int], error) {
+		calls++
+		return Page[int]{Items: []int{1, 2, 3}, Cursor: fmt.Sprintf("c%d", calls), HasNext: true}, nil
+	}, "").SetMaxItems(4)
+
+	items, err := p.All(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(items) != 4 {
+		t.Errorf("Expected 4 items, got %d", len(items))
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 page fetches, got %d", calls)
+	}
+}
+
+func TestPager_StopAndStalledCursor(t *testing.T) {
+	p := NewPager(func(ctx context.Context, cursor string) (Page[int], error) {
+		return Page[int]{Items: []int{1, 2}, Cursor: "same", HasNext: true}, nil
+	}, "same")
+
+	if !p.Next(context.Background()) {
+		t.Fatalf("Expected an item")
+	}
+	p.Stop()
+	if p.Next(context.Background()) {
+		t.Errorf("Expected Next to return false after Stop")
+	}
+
+	// a page that reports more results without advancing the cursor ends the iteration
+	p = NewPager(func(ctx context.Context, cursor string) (Page[int], error) {
+		return Page[int]{Items: []int{1, 2}, Cursor: "same", HasNext: true}, nil
+	}, "same")
+	items, err := p.All(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("Expected 2 items, got %d", len(items))
+	}
+}
+
+func TestPager_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewPager(func(ctx context.Context, cursor string) (Page[int], error) {
+		cancel()
+		return Page[int]{Items: []int{1}, Cursor: "next", HasNext: true}, nil
+	}, "")
+
+	items, err := p.All(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(items) != 1 {
+		t.Errorf("Expected 1 item before cancellation, got %d", len(items))
+	}
+}
+
+func TestApiClient_NewOrdersPager(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/batch", func(request *http.Request) (*http.Response, error) {
+		respBody := `{"orders":[{"order_id":"1"},{"order_id":"2"}],"has_next":true,"cursor":"page2"}`
+		if request.URL.Query().Get("cursor") == "page2" {
+			respBody = `{"orders":[{"order_id":"3"}],"has_next":false,"cursor":""}`
+		}
+		if request.URL.Query().Get("product_id") != "BTC-USD" {
+			return httpmock.NewStringResponse(http.StatusBadRequest, `{"message":"missing product_id"}`), nil
+		}
+		resp := httpmock.NewStringResponse(http.StatusOK, respBody)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	orders, err := api.NewOrdersPager(ListOrdersQuery{ProductId: "BTC-USD", Limit: 2}).All(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(orders) != 3 {
+		t.Fatalf("Expected 3 orders, got %d", len(orders))
+	}
+	if orders[2].OrderId != "3" {
+		t.Errorf("Expected 3, got %s", orders[2].OrderId)
+	}
+}
+
+func TestApiClient_NewFillsPager(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/fills", func(request *http.Request) (*http.Response, error) {
+		respBody := `{"fills":[{"entry_id":"1"}],"cursor":"page2"}`
+		if request.URL.Query().Get("cursor") == "page2" {
+			respBody = `{"fills":[{"entry_id":"2"}],"cursor":""}`
+		}
+		resp := httpmock.NewStringResponse(http.StatusOK, respBody)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	fills, err := api.NewFillsPager(ListFillsQuery{}).All(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(fills) != 2 {
+		t.Fatalf("Expected 2 fills, got %d", len(fills))
+	}
+}
+
+func TestApiClient_NewAccountsPager(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var limits []string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/accounts", func(request *http.Request) (*http.Response, error) {
+		limits = append(limits, request.URL.Query().Get("limit"))
+		respBody := `{"accounts":[{"uuid":"1"},{"uuid":"2"}],"has_next":true,"cursor":"page2","size":2}`
+		if request.URL.Query().Get("cursor") == "page2" {
+			respBody = `{"accounts":[{"uuid":"3"},{"uuid":"4"}],"has_next":true,"cursor":"page3","size":2}`
+		}
+		resp := httpmock.NewStringResponse(http.StatusOK, respBody)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	pager := api.NewAccountsPager(2).SetMaxItems(3)
+	var uuids []string
+	for pager.Next(context.Background()) {
+		uuids = append(uuids, pager.Item().Uuid)
+	}
+	if pager.Err() != nil {
+		t.Fatalf("Expected no error, got %s", pager.Err())
+	}
+	if len(uuids) != 3 {
+		t.Fatalf("Expected 3 accounts, got %d", len(uuids))
+	}
+	if pager.Cursor() != "page3" {
+		t.Errorf("Expected page3, got %s", pager.Cursor())
+	}
+	for _, l := range limits {
+		if l != "2" {
+			t.Errorf("Expected the limit to be passed through unchanged, got %s", l)
+		}
+	}
+}
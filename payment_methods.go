@@ -0,0 +1,61 @@
+package coinbasev3
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type PaymentMethod struct {
+	Id            string    `json:"id"`
+	Type          string    `json:"type"`
+	Name          string    `json:"name"`
+	Currency      string    `json:"currency"`
+	Verified      bool      `json:"verified"`
+	AllowBuy      bool      `json:"allow_buy"`
+	AllowSell     bool      `json:"allow_sell"`
+	AllowDeposit  bool      `json:"allow_deposit"`
+	AllowWithdraw bool      `json:"allow_withdraw"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ListPaymentMethods get a list of the payment methods linked to the user, such as bank accounts.
+func (c *ApiClient) ListPaymentMethods() ([]PaymentMethod, error) {
+	return c.ListPaymentMethodsWithContext(context.Background())
+}
+
+// ListPaymentMethodsWithContext is like ListPaymentMethods but binds the request to the given context.
+func (c *ApiClient) ListPaymentMethodsWithContext(ctx context.Context) ([]PaymentMethod, error) {
+	u := c.makeV3Url("/brokerage/payment_methods")
+
+	var data ListPaymentMethodsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return nil, err
+	}
+	return data.PaymentMethods, nil
+}
+
+type ListPaymentMethodsData struct {
+	PaymentMethods []PaymentMethod `json:"payment_methods"`
+}
+
+// GetPaymentMethod get information about a payment method, given its id.
+func (c *ApiClient) GetPaymentMethod(paymentMethodId string) (PaymentMethod, error) {
+	return c.GetPaymentMethodWithContext(context.Background(), paymentMethodId)
+}
+
+// GetPaymentMethodWithContext is like GetPaymentMethod but binds the request to the given context.
+func (c *ApiClient) GetPaymentMethodWithContext(ctx context.Context, paymentMethodId string) (PaymentMethod, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/payment_methods/%s", paymentMethodId))
+
+	var data GetPaymentMethodData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.PaymentMethod, err
+	}
+	return data.PaymentMethod, nil
+}
+
+type GetPaymentMethodData struct {
+	PaymentMethod PaymentMethod `json:"payment_method"`
+}
@@ -0,0 +1,31 @@
+package coinbasev3
+
+import (
+	"github.com/jarcoal/httpmock"
+	"testing"
+)
+
+func TestApiClient_PaymentMethods(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	method := `{"id":"pm-1","type":"ACH","name":"BANK *****1234","currency":"USD","verified":true,"allow_buy":true,"allow_sell":true,"allow_deposit":true,"allow_withdraw":false,"created_at":"2020-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}`
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/payment_methods", jsonResponder(`{"payment_methods":[`+method+`]}`))
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/payment_methods/pm-1", jsonResponder(`{"payment_method":`+method+`}`))
+
+	methods, err := api.ListPaymentMethods()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(methods) != 1 || methods[0].Id != "pm-1" {
+		t.Fatalf("Unexpected payment methods %+v", methods)
+	}
+
+	pm, err := api.GetPaymentMethod("pm-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if pm.Type != "ACH" || !pm.Verified || !pm.AllowDeposit || pm.AllowWithdraw || pm.CreatedAt.Year() != 2020 {
+		t.Errorf("Unexpected payment method %+v", pm)
+	}
+}
@@ -0,0 +1,240 @@
+package coinbasev3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type MarginType string
+
+const (
+	MarginTypeCross    MarginType = "CROSS"
+	MarginTypeIsolated MarginType = "ISOLATED"
+)
+
+type PerpetualsPositionSide string
+
+const (
+	PerpetualsPositionSideUnknown PerpetualsPositionSide = "POSITION_SIDE_UNKNOWN"
+	PerpetualsPositionSideLong    PerpetualsPositionSide = "POSITION_SIDE_LONG"
+	PerpetualsPositionSideShort   PerpetualsPositionSide = "POSITION_SIDE_SHORT"
+)
+
+// GetPerpetualsPortfolioSummary get the margin, collateral and PnL of the perpetuals (INTX) portfolio with the given uuid.
+func (c *ApiClient) GetPerpetualsPortfolioSummary(portfolioUuid string) (PerpetualsPortfolioSummaryData, error) {
+	return c.GetPerpetualsPortfolioSummaryWithContext(context.Background(), portfolioUuid)
+}
+
+// GetPerpetualsPortfolioSummaryWithContext is like GetPerpetualsPortfolioSummary but binds the request to the given context.
+func (c *ApiClient) GetPerpetualsPortfolioSummaryWithContext(ctx context.Context, portfolioUuid string) (PerpetualsPortfolioSummaryData, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/intx/portfolio/%s", portfolioUuid))
+
+	var data PerpetualsPortfolioSummaryData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type PerpetualsPortfolioSummaryData struct {
+	Portfolios []PerpetualsPortfolio `json:"portfolios"`
+	Summary    struct {
+		UnrealizedPnl       Amount `json:"unrealized_pnl"`
+		BuyingPower         Amount `json:"buying_power"`
+		TotalBalance        Amount `json:"total_balance"`
+		MaxWithdrawalAmount Amount `json:"max_withdrawal_amount"`
+	} `json:"summary"`
+}
+
+type PerpetualsPortfolio struct {
+	PortfolioUuid              string     `json:"portfolio_uuid"`
+	Collateral                 Decimal    `json:"collateral"`
+	PositionNotional           Decimal    `json:"position_notional"`
+	OpenPositionNotional       Decimal    `json:"open_position_notional"`
+	PendingFees                Decimal    `json:"pending_fees"`
+	Borrow                     Decimal    `json:"borrow"`
+	AccruedInterest            Decimal    `json:"accrued_interest"`
+	RollingDebt                Decimal    `json:"rolling_debt"`
+	PortfolioInitialMargin     Decimal    `json:"portfolio_initial_margin"`
+	PortfolioImNotional        Amount     `json:"portfolio_im_notional"`
+	PortfolioMaintenanceMargin Decimal    `json:"portfolio_maintenance_margin"`
+	PortfolioMmNotional        Amount     `json:"portfolio_mm_notional"`
+	LiquidationPercentage      Decimal    `json:"liquidation_percentage"`
+	LiquidationBuffer          Decimal    `json:"liquidation_buffer"`
+	MarginType                 MarginType `json:"margin_type"`
+	MarginFlags                string     `json:"margin_flags"`
+	LiquidationStatus          string     `json:"liquidation_status"`
+	UnrealizedPnl              Amount     `json:"unrealized_pnl"`
+	TotalBalance               Amount     `json:"total_balance"`
+}
+
+// ListPerpetualsPositions get a list of the open positions of the perpetuals portfolio with the given uuid.
+func (c *ApiClient) ListPerpetualsPositions(portfolioUuid string) (ListPerpetualsPositionsData, error) {
+	return c.ListPerpetualsPositionsWithContext(context.Background(), portfolioUuid)
+}
+
+// ListPerpetualsPositionsWithContext is like ListPerpetualsPositions but binds the request to the given context.
+func (c *ApiClient) ListPerpetualsPositionsWithContext(ctx context.Context, portfolioUuid string) (ListPerpetualsPositionsData, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/intx/positions/%s", portfolioUuid))
+
+	var data ListPerpetualsPositionsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type ListPerpetualsPositionsData struct {
+	Positions []PerpetualsPosition `json:"positions"`
+	Summary   struct {
+		AggregatedPnl Amount `json:"aggregated_pnl"`
+	} `json:"summary"`
+}
+
+// GetPerpetualsPosition get the open position of the perpetuals portfolio with the given uuid for a symbol, e.g. BTC-PERP-INTX.
+func (c *ApiClient) GetPerpetualsPosition(portfolioUuid, symbol string) (PerpetualsPosition, error) {
+	return c.GetPerpetualsPositionWithContext(context.Background(), portfolioUuid, symbol)
+}
+
+// GetPerpetualsPositionWithContext is like GetPerpetualsPosition but binds the request to the given context.
+func (c *ApiClient) GetPerpetualsPositionWithContext(ctx context.Context, portfolioUuid, symbol string) (PerpetualsPosition, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/intx/positions/%s/%s", portfolioUuid, symbol))
+
+	var data GetPerpetualsPositionData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Position, err
+	}
+	return data.Position, nil
+}
+
+type GetPerpetualsPositionData struct {
+	Position PerpetualsPosition `json:"position"`
+}
+
+type PerpetualsPosition struct {
+	ProductId        string                 `json:"product_id"`
+	ProductUuid      string                 `json:"product_uuid"`
+	PortfolioUuid    string                 `json:"portfolio_uuid"`
+	Symbol           string                 `json:"symbol"`
+	Vwap             Amount                 `json:"vwap"`
+	EntryVwap        Amount                 `json:"entry_vwap"`
+	PositionSide     PerpetualsPositionSide `json:"position_side"`
+	MarginType       MarginType             `json:"margin_type"`
+	NetSize          Decimal                `json:"net_size"`
+	BuyOrderSize     Decimal                `json:"buy_order_size"`
+	SellOrderSize    Decimal                `json:"sell_order_size"`
+	ImContribution   Decimal                `json:"im_contribution"`
+	UnrealizedPnl    Amount                 `json:"unrealized_pnl"`
+	MarkPrice        Amount                 `json:"mark_price"`
+	LiquidationPrice Amount                 `json:"liquidation_price"`
+	Leverage         Decimal                `json:"leverage"`
+	ImNotional       Amount                 `json:"im_notional"`
+	MmNotional       Amount                 `json:"mm_notional"`
+	PositionNotional Amount                 `json:"position_notional"`
+	AggregatedPnl    Amount                 `json:"aggregated_pnl"`
+}
+
+type AllocatePortfolioRequest struct {
+	// PortfolioUuid string The perpetuals portfolio to allocate funds to.
+	PortfolioUuid string `json:"portfolio_uuid"`
+	// Symbol string The isolated position to allocate funds to, e.g. BTC-PERP-INTX.
+	Symbol string `json:"symbol"`
+	// Amount Decimal The amount to allocate.
+	Amount Decimal `json:"amount"`
+	// Currency string The currency of the amount, e.g. USDC.
+	Currency string `json:"currency"`
+}
+
+// AllocatePortfolio allocate funds from the perpetuals portfolio to an isolated position.
+func (c *ApiClient) AllocatePortfolio(req AllocatePortfolioRequest) error {
+	return c.AllocatePortfolioWithContext(context.Background(), req)
+}
+
+// AllocatePortfolioWithContext is like AllocatePortfolio but binds the request to the given context.
+func (c *ApiClient) AllocatePortfolioWithContext(ctx context.Context, req AllocatePortfolioRequest) error {
+	u := c.makeV3Url("/brokerage/intx/allocate")
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return c.post(ctx, u, body, nil)
+}
+
+// GetPerpetualsPortfolioBalances get the asset balances and collateral values of the perpetuals portfolio with the given uuid.
+func (c *ApiClient) GetPerpetualsPortfolioBalances(portfolioUuid string) ([]PerpetualsPortfolioBalances, error) {
+	return c.GetPerpetualsPortfolioBalancesWithContext(context.Background(), portfolioUuid)
+}
+
+// GetPerpetualsPortfolioBalancesWithContext is like GetPerpetualsPortfolioBalances but binds the request to the given context.
+func (c *ApiClient) GetPerpetualsPortfolioBalancesWithContext(ctx context.Context, portfolioUuid string) ([]PerpetualsPortfolioBalances, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/intx/balances/%s", portfolioUuid))
+
+	var data GetPerpetualsPortfolioBalancesData
+	if err := c.get(ctx, u, &data); err != nil {
+		return nil, err
+	}
+	return data.PortfolioBalances, nil
+}
+
+type GetPerpetualsPortfolioBalancesData struct {
+	PortfolioBalances []PerpetualsPortfolioBalances `json:"portfolio_balances"`
+}
+
+type PerpetualsPortfolioBalances struct {
+	PortfolioUuid        string                       `json:"portfolio_uuid"`
+	Balances             []PerpetualsPortfolioBalance `json:"balances"`
+	IsMarginLimitReached bool                         `json:"is_margin_limit_reached"`
+}
+
+type PerpetualsPortfolioBalance struct {
+	Asset struct {
+		AssetId                string  `json:"asset_id"`
+		AssetUuid              string  `json:"asset_uuid"`
+		AssetName              string  `json:"asset_name"`
+		Status                 string  `json:"status"`
+		CollateralWeight       Decimal `json:"collateral_weight"`
+		AccountCollateralLimit Decimal `json:"account_collateral_limit"`
+	} `json:"asset"`
+	Quantity                     Decimal `json:"quantity"`
+	Hold                         Decimal `json:"hold"`
+	TransferHold                 Decimal `json:"transfer_hold"`
+	CollateralValue              Decimal `json:"collateral_value"`
+	CollateralWeight             Decimal `json:"collateral_weight"`
+	MaxWithdrawAmount            Decimal `json:"max_withdraw_amount"`
+	Loan                         Decimal `json:"loan"`
+	LoanCollateralRequirementUsd Decimal `json:"loan_collateral_requirement_usd"`
+	PledgedQuantity              Decimal `json:"pledged_quantity"`
+}
+
+// SetMultiAssetCollateral enable or disable multi-asset collateral for the perpetuals portfolio, which lets assets other than USDC back open positions.
+func (c *ApiClient) SetMultiAssetCollateral(portfolioUuid string, enabled bool) (bool, error) {
+	return c.SetMultiAssetCollateralWithContext(context.Background(), portfolioUuid, enabled)
+}
+
+// SetMultiAssetCollateralWithContext is like SetMultiAssetCollateral but binds the request to the given context.
+func (c *ApiClient) SetMultiAssetCollateralWithContext(ctx context.Context, portfolioUuid string, enabled bool) (bool, error) {
+	u := c.makeV3Url("/brokerage/intx/multi_asset_collateral")
+
+	// setting the same value twice has no further effect
+	ctx = withRetrySafe(ctx)
+
+	var data MultiAssetCollateralData
+	body, err := json.Marshal(struct {
+		PortfolioUuid               string `json:"portfolio_uuid"`
+		MultiAssetCollateralEnabled bool   `json:"multi_asset_collateral_enabled"`
+	}{portfolioUuid, enabled})
+	if err != nil {
+		return false, err
+	}
+
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return false, err
+	}
+	return data.MultiAssetCollateralEnabled, nil
+}
+
+type MultiAssetCollateralData struct {
+	MultiAssetCollateralEnabled bool `json:"multi_asset_collateral_enabled"`
+}
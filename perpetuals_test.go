@@ -0,0 +1,107 @@
+package coinbasev3
+
+import (
+	"github.com/jarcoal/httpmock"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestApiClient_GetPerpetualsPortfolioSummary(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/intx/portfolio/p-1",
+		jsonResponder(`{"portfolios":[{"portfolio_uuid":"p-1","collateral":"5000","position_notional":"12000","portfolio_initial_margin":"0.1","portfolio_im_notional":{"value":"1200","currency":"USDC"},"liquidation_percentage":"0.2","margin_type":"CROSS","unrealized_pnl":{"value":"-35.5","currency":"USDC"},"total_balance":{"value":"4964.5","currency":"USDC"}}],"summary":{"unrealized_pnl":{"value":"-35.5","currency":"USDC"},"buying_power":{"value":"37000","currency":"USDC"},"total_balance":{"value":"4964.5","currency":"USDC"},"max_withdrawal_amount":{"value":"3764.5","currency":"USDC"}}}`))
+
+	data, err := api.GetPerpetualsPortfolioSummary("p-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(data.Portfolios) != 1 || data.Portfolios[0].MarginType != MarginTypeCross || data.Portfolios[0].Collateral != "5000" {
+		t.Fatalf("Unexpected portfolios %+v", data.Portfolios)
+	}
+	if data.Summary.BuyingPower.Value != "37000" || data.Summary.UnrealizedPnl.Value.Sign() >= 0 {
+		t.Errorf("Unexpected summary %+v", data.Summary)
+	}
+}
+
+func TestApiClient_PerpetualsPositions(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	position := `{"product_id":"BTC-PERP-INTX","portfolio_uuid":"p-1","symbol":"BTC-PERP-INTX","vwap":{"value":"64000","currency":"USDC"},"entry_vwap":{"value":"63500","currency":"USDC"},"position_side":"POSITION_SIDE_SHORT","margin_type":"ISOLATED","net_size":"-0.2","unrealized_pnl":{"value":"-100","currency":"USDC"},"mark_price":{"value":"64000","currency":"USDC"},"liquidation_price":{"value":"79000","currency":"USDC"},"leverage":"5","aggregated_pnl":{"value":"-100","currency":"USDC"}}`
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/intx/positions/p-1",
+		jsonResponder(`{"positions":[`+position+`],"summary":{"aggregated_pnl":{"value":"-100","currency":"USDC"}}}`))
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/intx/positions/p-1/BTC-PERP-INTX",
+		jsonResponder(`{"position":`+position+`}`))
+
+	list, err := api.ListPerpetualsPositions("p-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(list.Positions) != 1 || list.Summary.AggregatedPnl.Value != "-100" {
+		t.Fatalf("Unexpected positions %+v", list)
+	}
+
+	p, err := api.GetPerpetualsPosition("p-1", "BTC-PERP-INTX")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if p.PositionSide != PerpetualsPositionSideShort || p.MarginType != MarginTypeIsolated {
+		t.Errorf("Unexpected position side or margin type %+v", p)
+	}
+	if p.Leverage != "5" || p.LiquidationPrice.Value != "79000" || p.NetSize.Sign() >= 0 {
+		t.Errorf("Unexpected position %+v", p)
+	}
+}
+
+func TestApiClient_AllocatePortfolio(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var body string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/intx/allocate", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		body = string(b)
+		return jsonResponder(`{}`)(request)
+	})
+
+	err := api.AllocatePortfolio(AllocatePortfolioRequest{PortfolioUuid: "p-1", Symbol: "BTC-PERP-INTX", Amount: "500", Currency: "USDC"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if body != `{"portfolio_uuid":"p-1","symbol":"BTC-PERP-INTX","amount":"500","currency":"USDC"}` {
+		t.Errorf("Unexpected request body %s", body)
+	}
+}
+
+func TestApiClient_PerpetualsCollateral(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var body string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/intx/balances/p-1",
+		jsonResponder(`{"portfolio_balances":[{"portfolio_uuid":"p-1","balances":[{"asset":{"asset_id":"BTC","asset_name":"Bitcoin","collateral_weight":"0.9"},"quantity":"0.5","hold":"0","collateral_value":"28800","max_withdraw_amount":"0.4"}],"is_margin_limit_reached":false}]}`))
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/intx/multi_asset_collateral", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		body = string(b)
+		return jsonResponder(`{"multi_asset_collateral_enabled":true}`)(request)
+	})
+
+	balances, err := api.GetPerpetualsPortfolioBalances("p-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(balances) != 1 || len(balances[0].Balances) != 1 || balances[0].Balances[0].Asset.CollateralWeight != "0.9" {
+		t.Fatalf("Unexpected balances %+v", balances)
+	}
+
+	enabled, err := api.SetMultiAssetCollateral("p-1", true)
+	if err != nil || !enabled {
+		t.Fatalf("Expected multi-asset collateral to be enabled, got %v", err)
+	}
+	if body != `{"portfolio_uuid":"p-1","multi_asset_collateral_enabled":true}` {
+		t.Errorf("Unexpected request body %s", body)
+	}
+}
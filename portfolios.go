@@ -0,0 +1,246 @@
+package coinbasev3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type PortfolioType string
+
+const (
+	PortfolioTypeUndefined PortfolioType = "UNDEFINED"
+	PortfolioTypeDefault   PortfolioType = "DEFAULT"
+	PortfolioTypeConsumer  PortfolioType = "CONSUMER"
+	PortfolioTypeIntx      PortfolioType = "INTX"
+)
+
+type Portfolio struct {
+	Name    string        `json:"name"`
+	Uuid    string        `json:"uuid"`
+	Type    PortfolioType `json:"type"`
+	Deleted bool          `json:"deleted"`
+}
+
+// Amount is a value in a given currency.
+type Amount struct {
+	Value    Decimal `json:"value"`
+	Currency string  `json:"currency"`
+}
+
+// ListPortfolios get a list of all portfolios of the user. An empty portfolio type returns portfolios of every type.
+func (c *ApiClient) ListPortfolios(portfolioType PortfolioType) (ListPortfoliosData, error) {
+	return c.ListPortfoliosWithContext(context.Background(), portfolioType)
+}
+
+// ListPortfoliosWithContext is like ListPortfolios but binds the request to the given context.
+func (c *ApiClient) ListPortfoliosWithContext(ctx context.Context, portfolioType PortfolioType) (ListPortfoliosData, error) {
+	query := ""
+	if portfolioType != "" {
+		query = fmt.Sprintf("?portfolio_type=%s", portfolioType)
+	}
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/portfolios%s", query))
+
+	var data ListPortfoliosData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type ListPortfoliosData struct {
+	Portfolios []Portfolio `json:"portfolios"`
+}
+
+// CreatePortfolio create a portfolio with the given name.
+func (c *ApiClient) CreatePortfolio(name string) (Portfolio, error) {
+	return c.CreatePortfolioWithContext(context.Background(), name)
+}
+
+// CreatePortfolioWithContext is like CreatePortfolio but binds the request to the given context.
+func (c *ApiClient) CreatePortfolioWithContext(ctx context.Context, name string) (Portfolio, error) {
+	u := c.makeV3Url("/brokerage/portfolios")
+
+	var data PortfolioData
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return data.Portfolio, err
+	}
+
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data.Portfolio, err
+	}
+	return data.Portfolio, nil
+}
+
+// EditPortfolio rename the portfolio with the given uuid.
+func (c *ApiClient) EditPortfolio(uuid, name string) (Portfolio, error) {
+	return c.EditPortfolioWithContext(context.Background(), uuid, name)
+}
+
+// EditPortfolioWithContext is like EditPortfolio but binds the request to the given context.
+func (c *ApiClient) EditPortfolioWithContext(ctx context.Context, uuid, name string) (Portfolio, error) {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/portfolios/%s", uuid))
+
+	var data PortfolioData
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return data.Portfolio, err
+	}
+
+	if err := c.put(ctx, u, body, &data); err != nil {
+		return data.Portfolio, err
+	}
+	return data.Portfolio, nil
+}
+
+type PortfolioData struct {
+	Portfolio Portfolio `json:"portfolio"`
+}
+
+// DeletePortfolio delete the portfolio with the given uuid.
+func (c *ApiClient) DeletePortfolio(uuid string) error {
+	return c.DeletePortfolioWithContext(context.Background(), uuid)
+}
+
+// DeletePortfolioWithContext is like DeletePortfolio but binds the request to the given context.
+func (c *ApiClient) DeletePortfolioWithContext(ctx context.Context, uuid string) error {
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/portfolios/%s", uuid))
+	return c.delete(ctx, u, nil)
+}
+
+type MovePortfolioFundsRequest struct {
+	// Funds Amount The amount and currency to move.
+	Funds Amount `json:"funds"`
+	// SourcePortfolioUuid string The portfolio to move the funds from.
+	SourcePortfolioUuid string `json:"source_portfolio_uuid"`
+	// TargetPortfolioUuid string The portfolio to move the funds to.
+	TargetPortfolioUuid string `json:"target_portfolio_uuid"`
+}
+
+// MovePortfolioFunds move funds between two portfolios.
+func (c *ApiClient) MovePortfolioFunds(req MovePortfolioFundsRequest) (MovePortfolioFundsData, error) {
+	return c.MovePortfolioFundsWithContext(context.Background(), req)
+}
+
+// MovePortfolioFundsWithContext is like MovePortfolioFunds but binds the request to the given context.
+func (c *ApiClient) MovePortfolioFundsWithContext(ctx context.Context, req MovePortfolioFundsRequest) (MovePortfolioFundsData, error) {
+	u := c.makeV3Url("/brokerage/portfolios/move_funds")
+
+	var data MovePortfolioFundsData
+	body, err := json.Marshal(req)
+	if err != nil {
+		return data, err
+	}
+
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type MovePortfolioFundsData struct {
+	SourcePortfolioUuid string `json:"source_portfolio_uuid"`
+	TargetPortfolioUuid string `json:"target_portfolio_uuid"`
+}
+
+// GetPortfolioBreakdown get the balances and positions of the portfolio with the given uuid. An empty currency reports the fiat values in the user's native currency.
+func (c *ApiClient) GetPortfolioBreakdown(uuid, currency string) (PortfolioBreakdown, error) {
+	return c.GetPortfolioBreakdownWithContext(context.Background(), uuid, currency)
+}
+
+// GetPortfolioBreakdownWithContext is like GetPortfolioBreakdown but binds the request to the given context.
+func (c *ApiClient) GetPortfolioBreakdownWithContext(ctx context.Context, uuid, currency string) (PortfolioBreakdown, error) {
+	query := ""
+	if currency != "" {
+		query = fmt.Sprintf("?currency=%s", currency)
+	}
+	u := c.makeV3Url(fmt.Sprintf("/brokerage/portfolios/%s%s", uuid, query))
+
+	var data GetPortfolioBreakdownData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Breakdown, err
+	}
+	return data.Breakdown, nil
+}
+
+type GetPortfolioBreakdownData struct {
+	Breakdown PortfolioBreakdown `json:"breakdown"`
+}
+
+type PortfolioBreakdown struct {
+	Portfolio         Portfolio                  `json:"portfolio"`
+	PortfolioBalances PortfolioBalances          `json:"portfolio_balances"`
+	SpotPositions     []PortfolioSpotPosition    `json:"spot_positions"`
+	PerpPositions     []PortfolioPerpPosition    `json:"perp_positions"`
+	FuturesPositions  []PortfolioFuturesPosition `json:"futures_positions"`
+}
+
+type PortfolioBalances struct {
+	TotalBalance               Amount `json:"total_balance"`
+	TotalFuturesBalance        Amount `json:"total_futures_balance"`
+	TotalCashEquivalentBalance Amount `json:"total_cash_equivalent_balance"`
+	TotalCryptoBalance         Amount `json:"total_crypto_balance"`
+	FuturesUnrealizedPnl       Amount `json:"futures_unrealized_pnl"`
+	PerpUnrealizedPnl          Amount `json:"perp_unrealized_pnl"`
+}
+
+type PortfolioSpotPosition struct {
+	Asset                string  `json:"asset"`
+	AccountUuid          string  `json:"account_uuid"`
+	TotalBalanceFiat     Decimal `json:"total_balance_fiat"`
+	TotalBalanceCrypto   Decimal `json:"total_balance_crypto"`
+	AvailableToTradeFiat Decimal `json:"available_to_trade_fiat"`
+	Allocation           Decimal `json:"allocation"`
+	OneDayChange         Decimal `json:"one_day_change"`
+	CostBasis            Amount  `json:"cost_basis"`
+	AssetImgUrl          string  `json:"asset_img_url"`
+	IsCash               bool    `json:"is_cash"`
+}
+
+type PortfolioPerpPosition struct {
+	ProductId             string                 `json:"product_id"`
+	ProductUuid           string                 `json:"product_uuid"`
+	Symbol                string                 `json:"symbol"`
+	AssetImageUrl         string                 `json:"asset_image_url"`
+	PositionSide          PerpetualsPositionSide `json:"position_side"`
+	MarginType            MarginType             `json:"margin_type"`
+	NetSize               Decimal                `json:"net_size"`
+	BuyOrderSize          Decimal                `json:"buy_order_size"`
+	SellOrderSize         Decimal                `json:"sell_order_size"`
+	ImContribution        Decimal                `json:"im_contribution"`
+	Leverage              Decimal                `json:"leverage"`
+	LiquidationBuffer     Decimal                `json:"liquidation_buffer"`
+	LiquidationPercentage Decimal                `json:"liquidation_percentage"`
+}
+
+type PortfolioFuturesPosition struct {
+	ProductId       string    `json:"product_id"`
+	ContractSize    Decimal   `json:"contract_size"`
+	Side            string    `json:"side"`
+	Amount          Decimal   `json:"amount"`
+	AvgEntryPrice   Decimal   `json:"avg_entry_price"`
+	CurrentPrice    Decimal   `json:"current_price"`
+	UnrealizedPnl   Decimal   `json:"unrealized_pnl"`
+	Expiry          time.Time `json:"expiry"`
+	UnderlyingAsset string    `json:"underlying_asset"`
+	AssetImgUrl     string    `json:"asset_img_url"`
+	ProductName     string    `json:"product_name"`
+	Venue           string    `json:"venue"`
+	NotionalValue   Decimal   `json:"notional_value"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase may send an empty expiry.
+func (p *PortfolioFuturesPosition) UnmarshalJSON(data []byte) error {
+	type alias PortfolioFuturesPosition
+	aux := struct {
+		Expiry flexTime `json:"expiry"`
+		*alias
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Expiry = time.Time(aux.Expiry)
+	return nil
+}
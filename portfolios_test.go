@@ -0,0 +1,168 @@
+package coinbasev3
+
+import (
+	"github.com/jarcoal/httpmock"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func jsonResponder(body string) httpmock.Responder {
+	return func(request *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(http.StatusOK, body)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	}
+}
+
+func TestApiClient_ListPortfolios(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/portfolios", "portfolio_type=CONSUMER",
+		jsonResponder(`{"portfolios":[{"name":"Default","uuid":"p-1","type":"DEFAULT","deleted":false},{"name":"Grid","uuid":"p-2","type":"CONSUMER","deleted":false}]}`))
+
+	data, err := api.ListPortfolios(PortfolioTypeConsumer)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(data.Portfolios) != 2 {
+		t.Fatalf("Expected 2 portfolios, got %d", len(data.Portfolios))
+	}
+	if data.Portfolios[1].Uuid != "p-2" || data.Portfolios[1].Type != PortfolioTypeConsumer {
+		t.Errorf("Unexpected portfolio %+v", data.Portfolios[1])
+	}
+}
+
+func TestApiClient_CreateEditDeletePortfolio(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var createBody, editBody string
+	deleted := false
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/portfolios", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		createBody = string(b)
+		return jsonResponder(`{"portfolio":{"name":"Grid","uuid":"p-2","type":"CONSUMER","deleted":false}}`)(request)
+	})
+	httpmock.RegisterResponder("PUT", "https://api.coinbase.com/api/v3/brokerage/portfolios/p-2", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		editBody = string(b)
+		return jsonResponder(`{"portfolio":{"name":"Momentum","uuid":"p-2","type":"CONSUMER","deleted":false}}`)(request)
+	})
+	httpmock.RegisterResponder("DELETE", "https://api.coinbase.com/api/v3/brokerage/portfolios/p-2", func(request *http.Request) (*http.Response, error) {
+		deleted = true
+		return jsonResponder(`{}`)(request)
+	})
+
+	p, err := api.CreatePortfolio("Grid")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if p.Uuid != "p-2" || createBody != `{"name":"Grid"}` {
+		t.Errorf("Unexpected portfolio %+v for body %s", p, createBody)
+	}
+
+	p, err = api.EditPortfolio("p-2", "Momentum")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if p.Name != "Momentum" || editBody != `{"name":"Momentum"}` {
+		t.Errorf("Unexpected portfolio %+v for body %s", p, editBody)
+	}
+
+	if err := api.DeletePortfolio("p-2"); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !deleted {
+		t.Errorf("Expected the portfolio to be deleted")
+	}
+}
+
+func TestApiClient_MovePortfolioFunds(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var body string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/portfolios/move_funds", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		body = string(b)
+		return jsonResponder(`{"source_portfolio_uuid":"p-1","target_portfolio_uuid":"p-2"}`)(request)
+	})
+
+	data, err := api.MovePortfolioFunds(MovePortfolioFundsRequest{
+		Funds:               Amount{Value: "100.50", Currency: "USD"},
+		SourcePortfolioUuid: "p-1",
+		TargetPortfolioUuid: "p-2",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if data.TargetPortfolioUuid != "p-2" {
+		t.Errorf("Unexpected response %+v", data)
+	}
+	if body != `{"funds":{"value":"100.50","currency":"USD"},"source_portfolio_uuid":"p-1","target_portfolio_uuid":"p-2"}` {
+		t.Errorf("Unexpected request body %s", body)
+	}
+}
+
+func TestApiClient_GetPortfolioBreakdown(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/portfolios/p-2", "currency=USD",
+		jsonResponder(`{"breakdown":{"portfolio":{"name":"Grid","uuid":"p-2","type":"CONSUMER","deleted":false},"portfolio_balances":{"total_balance":{"value":"1250.75","currency":"USD"},"total_crypto_balance":{"value":"250.75","currency":"USD"}},"spot_positions":[{"asset":"BTC","account_uuid":"a-1","total_balance_fiat":250.75,"total_balance_crypto":0.00625,"available_to_trade_fiat":250.75,"allocation":0.2,"cost_basis":{"value":"240","currency":"USD"},"is_cash":false}],"perp_positions":[],"futures_positions":[]}}`))
+
+	data, err := api.GetPortfolioBreakdown("p-2", "USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if data.Portfolio.Name != "Grid" {
+		t.Errorf("Unexpected portfolio %+v", data.Portfolio)
+	}
+	if data.PortfolioBalances.TotalBalance.Value != "1250.75" {
+		t.Errorf("Expected a total balance of 1250.75, got %s", data.PortfolioBalances.TotalBalance.Value)
+	}
+	if len(data.SpotPositions) != 1 || !data.SpotPositions[0].TotalBalanceCrypto.Equal("0.00625") {
+		t.Fatalf("Unexpected spot positions %+v", data.SpotPositions)
+	}
+}
+
+func TestApiClient_RetailPortfolioId(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var orderBody string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/accounts", "limit=10&retail_portfolio_id=p-2",
+		jsonResponder(`{"accounts":[{"uuid":"a-1","currency":"BTC"}],"has_next":false}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/batch", "retail_portfolio_id=p-2",
+		jsonResponder(`{"orders":[{"order_id":"o-1"}],"has_next":false}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/fills", "retail_portfolio_id=p-2",
+		jsonResponder(`{"fills":[{"entry_id":"f-1"}]}`))
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		orderBody = string(b)
+		return jsonResponder(`{"success":true,"order_id":"o-2"}`)(request)
+	})
+
+	accounts, err := api.QueryAccounts(ListAccountsQuery{Limit: 10, RetailPortfolioId: "p-2"})
+	if err != nil || len(accounts.Accounts) != 1 {
+		t.Fatalf("Unexpected accounts %+v: %v", accounts, err)
+	}
+	orders, err := api.GetListOrders(ListOrdersQuery{RetailPortfolioId: "p-2"})
+	if err != nil || len(orders.Orders) != 1 {
+		t.Fatalf("Unexpected orders %+v: %v", orders, err)
+	}
+	fills, err := api.GetListFills(ListFillsQuery{RetailPortfolioId: "p-2"})
+	if err != nil || len(fills.Fills) != 1 {
+		t.Fatalf("Unexpected fills %+v: %v", fills, err)
+	}
+
+	req, _ := MarketBuy("BTC-USD", "10").ClientOrderId("0000").Portfolio("p-2").Build()
+	if _, err := api.CreateOrder(req); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if orderBody != `{"client_order_id":"0000","product_id":"BTC-USD","side":"BUY","order_configuration":{"market_market_ioc":{"quote_size":"10"}},"retail_portfolio_id":"p-2"}` {
+		t.Errorf("Unexpected request body %s", orderBody)
+	}
+}
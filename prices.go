@@ -1,26 +1,44 @@
 package coinbasev3
 
+import "context"
+
 // GetBuyPrice get the total price to buy a currency.
 func (c *ApiClient) GetBuyPrice(pair string) (CurrencyPairPrice, error) {
-	return c.getPairPrice(pair, "buy")
+	return c.getPairPrice(context.Background(), pair, "buy")
+}
+
+// GetBuyPriceWithContext is like GetBuyPrice but binds the request to the given context.
+func (c *ApiClient) GetBuyPriceWithContext(ctx context.Context, pair string) (CurrencyPairPrice, error) {
+	return c.getPairPrice(ctx, pair, "buy")
 }
 
 // GetSellPrice get the total price to sell a currency.
 func (c *ApiClient) GetSellPrice(pair string) (CurrencyPairPrice, error) {
-	return c.getPairPrice(pair, "sell")
+	return c.getPairPrice(context.Background(), pair, "sell")
+}
+
+// GetSellPriceWithContext is like GetSellPrice but binds the request to the given context.
+func (c *ApiClient) GetSellPriceWithContext(ctx context.Context, pair string) (CurrencyPairPrice, error) {
+	return c.getPairPrice(ctx, pair, "sell")
 }
 
 // GetSpotPrice get the current market price of a currency.
 func (c *ApiClient) GetSpotPrice(pair string) (CurrencyPairPrice, error) {
-	return c.getPairPrice(pair, "spot")
+	return c.getPairPrice(context.Background(), pair, "spot")
+}
+
+// GetSpotPriceWithContext is like GetSpotPrice but binds the request to the given context.
+func (c *ApiClient) GetSpotPriceWithContext(ctx context.Context, pair string) (CurrencyPairPrice, error) {
+	return c.getPairPrice(ctx, pair, "spot")
 }
 
 // getPairPrice get the price of a currency pair.
-func (c *ApiClient) getPairPrice(pair string, side string) (CurrencyPairPrice, error) {
+func (c *ApiClient) getPairPrice(ctx context.Context, pair string, side string) (CurrencyPairPrice, error) {
 	u := "https://api.coinbase.com/v2/prices/{currency_pair}/{side}"
 
 	var price CurrencyPairPrice
 	resp, err := c.client.R().
+		SetContext(ctx).
 		SetPathParam("currency_pair", pair).
 		SetPathParam("side", side).
 		SetSuccessResult(&price).Get(u)
@@ -29,7 +47,7 @@ func (c *ApiClient) getPairPrice(pair string, side string) (CurrencyPairPrice, e
 	}
 
 	if !resp.IsSuccessState() {
-		return price, ErrFailedToUnmarshal
+		return price, newResponseError(resp)
 	}
 
 	return price, nil
@@ -38,7 +56,7 @@ func (c *ApiClient) getPairPrice(pair string, side string) (CurrencyPairPrice, e
 // CurrencyPairPrice represents the price of a currency pair.
 type CurrencyPairPrice struct {
 	Data struct {
-		Amount   string `json:"amount"`
-		Currency string `json:"currency"`
+		Amount   Decimal `json:"amount"`
+		Currency string  `json:"currency"`
 	} `json:"data"`
 }
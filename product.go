@@ -1,44 +1,41 @@
 package coinbasev3
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // GetProduct get information on a single product by product ID.
 func (c *ApiClient) GetProduct(productId string) (Product, error) {
-	u := c.makeV3Url(fmt.Sprintf("/brokerage/products/%s", productId))
-
-	var data Product
-	resp, err := c.httpClient.Get(u)
-	if err != nil {
-		return data, err
-	}
+	return c.GetProductWithContext(context.Background(), productId)
+}
 
-	if !resp.IsSuccessState() {
-		return data, ErrFailedToUnmarshal
-	}
+// GetProductWithContext is like GetProduct but binds the request to the given context.
+func (c *ApiClient) GetProductWithContext(ctx context.Context, productId string) (Product, error) {
+	u := c.makeMarketUrl(fmt.Sprintf("/brokerage/products/%s", productId))
 
-	err = resp.Unmarshal(&data)
-	if err != nil {
+	var data Product
+	if err := c.get(ctx, u, &data); err != nil {
 		return data, err
 	}
-
 	return data, nil
 }
 
 type Product struct {
 	ProductId                 string                   `json:"product_id"`
-	Price                     string                   `json:"price"`
-	PricePercentageChange24H  string                   `json:"price_percentage_change_24h"`
-	Volume24H                 string                   `json:"volume_24h"`
-	VolumePercentageChange24H string                   `json:"volume_percentage_change_24h"`
-	BaseIncrement             string                   `json:"base_increment"`
-	QuoteIncrement            string                   `json:"quote_increment"`
-	QuoteMinSize              string                   `json:"quote_min_size"`
-	QuoteMaxSize              string                   `json:"quote_max_size"`
-	BaseMinSize               string                   `json:"base_min_size"`
-	BaseMaxSize               string                   `json:"base_max_size"`
+	Price                     Decimal                  `json:"price"`
+	PricePercentageChange24H  Decimal                  `json:"price_percentage_change_24h"`
+	Volume24H                 Decimal                  `json:"volume_24h"`
+	VolumePercentageChange24H Decimal                  `json:"volume_percentage_change_24h"`
+	BaseIncrement             Decimal                  `json:"base_increment"`
+	QuoteIncrement            Decimal                  `json:"quote_increment"`
+	QuoteMinSize              Decimal                  `json:"quote_min_size"`
+	QuoteMaxSize              Decimal                  `json:"quote_max_size"`
+	BaseMinSize               Decimal                  `json:"base_min_size"`
+	BaseMaxSize               Decimal                  `json:"base_max_size"`
 	BaseName                  string                   `json:"base_name"`
 	QuoteName                 string                   `json:"quote_name"`
 	Watched                   bool                     `json:"watched"`
@@ -54,27 +51,61 @@ type Product struct {
 	QuoteCurrencyId           string                   `json:"quote_currency_id"`
 	BaseCurrencyId            string                   `json:"base_currency_id"`
 	FcmTradingSessionDetails  FcmTradingSessionDetails `json:"fcm_trading_session_details"`
-	MidMarketPrice            string                   `json:"mid_market_price"`
+	MidMarketPrice            Decimal                  `json:"mid_market_price"`
 	Alias                     string                   `json:"alias"`
 	AliasTo                   []string                 `json:"alias_to"`
 	BaseDisplaySymbol         string                   `json:"base_display_symbol"`
 	QuoteDisplaySymbol        string                   `json:"quote_display_symbol"`
 	ViewOnly                  bool                     `json:"view_only"`
-	PriceIncrement            string                   `json:"price_increment"`
+	PriceIncrement            Decimal                  `json:"price_increment"`
 	FutureProductDetails      FutureProductDetails     `json:"future_product_details"`
 }
 
+// RoundPrice truncates a price to the product's price increment, falling back to the quote increment.
+func (p Product) RoundPrice(price Decimal) Decimal {
+	if p.PriceIncrement.IsValid() && !p.PriceIncrement.IsZero() {
+		return price.RoundToIncrement(p.PriceIncrement)
+	}
+	return price.RoundToIncrement(p.QuoteIncrement)
+}
+
+// RoundBaseSize truncates a size in the base currency to the product's base increment.
+func (p Product) RoundBaseSize(size Decimal) Decimal {
+	return size.RoundToIncrement(p.BaseIncrement)
+}
+
+// RoundQuoteSize truncates a size in the quote currency to the product's quote increment.
+func (p Product) RoundQuoteSize(size Decimal) Decimal {
+	return size.RoundToIncrement(p.QuoteIncrement)
+}
+
 type FcmTradingSessionDetails struct {
-	IsSessionOpen string `json:"is_session_open"`
-	OpenTime      string `json:"open_time"`
-	CloseTime     string `json:"close_time"`
+	IsSessionOpen string    `json:"is_session_open"`
+	OpenTime      time.Time `json:"open_time"`
+	CloseTime     time.Time `json:"close_time"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase sends an empty string when there is no session.
+func (d *FcmTradingSessionDetails) UnmarshalJSON(data []byte) error {
+	type alias FcmTradingSessionDetails
+	aux := struct {
+		OpenTime  flexTime `json:"open_time"`
+		CloseTime flexTime `json:"close_time"`
+		*alias
+	}{alias: (*alias)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.OpenTime = time.Time(aux.OpenTime)
+	d.CloseTime = time.Time(aux.CloseTime)
+	return nil
 }
 
 type FutureProductDetails struct {
 	Venue                  string           `json:"venue"`
 	ContractCode           string           `json:"contract_code"`
-	ContractExpiry         string           `json:"contract_expiry"`
-	ContractSize           string           `json:"contract_size"`
+	ContractExpiry         time.Time        `json:"contract_expiry"`
+	ContractSize           Decimal          `json:"contract_size"`
 	ContractRootUnit       string           `json:"contract_root_unit"`
 	GroupDescription       string           `json:"group_description"`
 	ContractExpiryTimezone string           `json:"contract_expiry_timezone"`
@@ -85,53 +116,153 @@ type FutureProductDetails struct {
 	ContractDisplayName    string           `json:"contract_display_name"`
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase sends an empty contract expiry for spot products.
+func (d *FutureProductDetails) UnmarshalJSON(data []byte) error {
+	type alias FutureProductDetails
+	aux := struct {
+		ContractExpiry flexTime `json:"contract_expiry"`
+		*alias
+	}{alias: (*alias)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.ContractExpiry = time.Time(aux.ContractExpiry)
+	return nil
+}
+
 type PerpetualDetails struct {
-	OpenInterest string `json:"open_interest"`
-	FundingRate  string `json:"funding_rate"`
-	FundingTime  string `json:"funding_time"`
+	OpenInterest Decimal   `json:"open_interest"`
+	FundingRate  Decimal   `json:"funding_rate"`
+	FundingTime  time.Time `json:"funding_time"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase sends an empty funding time for products that are not perpetuals.
+func (d *PerpetualDetails) UnmarshalJSON(data []byte) error {
+	type alias PerpetualDetails
+	aux := struct {
+		FundingTime flexTime `json:"funding_time"`
+		*alias
+	}{alias: (*alias)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.FundingTime = time.Time(aux.FundingTime)
+	return nil
 }
 
 // GetProducts gets a list of available currency pairs for trading.
 func (c *ApiClient) GetProducts() ([]Products, error) {
+	return c.GetProductsWithContext(context.Background())
+}
+
+// GetProductsWithContext is like GetProducts but binds the request to the given context.
+func (c *ApiClient) GetProductsWithContext(ctx context.Context) ([]Products, error) {
 	u := c.makeExchangeUrl("/products")
 
 	var data []Products
-	resp, err := c.httpClient.Get(u)
-	if err != nil {
+	if err := c.get(ctx, u, &data); err != nil {
 		return nil, err
 	}
+	return data, nil
+}
+
+type ExpiringContractStatus string
+
+const (
+	ExpiringContractStatusUnexpired ExpiringContractStatus = "STATUS_UNEXPIRED"
+	ExpiringContractStatusExpired   ExpiringContractStatus = "STATUS_EXPIRED"
+	ExpiringContractStatusAll       ExpiringContractStatus = "STATUS_ALL"
+)
+
+type ListProductsQuery struct {
+	// Limit int32 A limit describing how many products to return.
+	Limit int32
+	// Offset int32 Number of products to offset before returning.
+	Offset int32
+	// ProductType string SPOT, FUTURE -- Only products matching this type are returned.
+	ProductType ProductType
+	// ProductIds []string List of product IDs to return.
+	ProductIds []string
+	// ContractExpiryType string EXPIRING, UNKNOWN_CONTRACT -- Only products matching this contract expiry type are returned. Only filters response if ProductType is set to FUTURE.
+	ContractExpiryType ContractExpiryType
+	// ExpiringContractStatus string STATUS_UNEXPIRED (default), STATUS_EXPIRED, STATUS_ALL -- Only expiring contracts matching this status are returned.
+	ExpiringContractStatus ExpiringContractStatus
+	// GetAllProducts bool If true, return all products of all product types, including expired futures contracts.
+	GetAllProducts bool
+}
+
+// BuildQueryString creates a query string from the request parameters. If no parameters are set, an empty string is returned.
+func (q ListProductsQuery) BuildQueryString() string {
+	var sb strings.Builder
 
-	if !resp.IsSuccessState() {
-		return nil, ErrFailedToUnmarshal
+	if q.Limit > 0 {
+		sb.WriteString(fmt.Sprintf("&limit=%d", q.Limit))
+	}
+	if q.Offset > 0 {
+		sb.WriteString(fmt.Sprintf("&offset=%d", q.Offset))
+	}
+	if q.ProductType != "" {
+		sb.WriteString(fmt.Sprintf("&product_type=%s", q.ProductType))
+	}
+	for _, id := range q.ProductIds {
+		sb.WriteString(fmt.Sprintf("&product_ids=%s", id))
+	}
+	if q.ContractExpiryType != "" {
+		sb.WriteString(fmt.Sprintf("&contract_expiry_type=%s", q.ContractExpiryType))
+	}
+	if q.ExpiringContractStatus != "" {
+		sb.WriteString(fmt.Sprintf("&expiring_contract_status=%s", q.ExpiringContractStatus))
+	}
+	if q.GetAllProducts {
+		sb.WriteString("&get_all_products=true")
 	}
 
-	err = resp.Unmarshal(&data)
-	if err != nil {
-		return nil, err
+	if sb.Len() > 0 {
+		return fmt.Sprintf("?%s", sb.String()[1:])
 	}
+	return ""
+}
+
+// ListProducts get a list of the available currency pairs for trading, filtered by optional query parameters. Unlike GetProducts, the full Product model is returned.
+func (c *ApiClient) ListProducts(q ListProductsQuery) (ListProductsData, error) {
+	return c.ListProductsWithContext(context.Background(), q)
+}
 
+// ListProductsWithContext is like ListProducts but binds the request to the given context.
+func (c *ApiClient) ListProductsWithContext(ctx context.Context, q ListProductsQuery) (ListProductsData, error) {
+	u := c.makeMarketUrl(fmt.Sprintf("/brokerage/products%s", q.BuildQueryString()))
+
+	var data ListProductsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
 	return data, nil
 }
 
+type ListProductsData struct {
+	Products    []Product `json:"products"`
+	NumProducts int32     `json:"num_products"`
+}
+
 type Products struct {
-	Id                     string `json:"id"`
-	BaseCurrency           string `json:"base_currency"`
-	QuoteCurrency          string `json:"quote_currency"`
-	QuoteIncrement         string `json:"quote_increment"`
-	BaseIncrement          string `json:"base_increment"`
-	DisplayName            string `json:"display_name"`
-	MinMarketFunds         string `json:"min_market_funds"`
-	MarginEnabled          bool   `json:"margin_enabled"`
-	PostOnly               bool   `json:"post_only"`
-	LimitOnly              bool   `json:"limit_only"`
-	CancelOnly             bool   `json:"cancel_only"`
-	Status                 string `json:"status"`
-	StatusMessage          string `json:"status_message"`
-	TradingDisabled        bool   `json:"trading_disabled"`
-	FxStablecoin           bool   `json:"fx_stablecoin"`
-	MaxSlippagePercentage  string `json:"max_slippage_percentage"`
-	AuctionMode            bool   `json:"auction_mode"`
-	HighBidLimitPercentage string `json:"high_bid_limit_percentage"`
+	Id                     string  `json:"id"`
+	BaseCurrency           string  `json:"base_currency"`
+	QuoteCurrency          string  `json:"quote_currency"`
+	QuoteIncrement         Decimal `json:"quote_increment"`
+	BaseIncrement          Decimal `json:"base_increment"`
+	DisplayName            string  `json:"display_name"`
+	MinMarketFunds         Decimal `json:"min_market_funds"`
+	MarginEnabled          bool    `json:"margin_enabled"`
+	PostOnly               bool    `json:"post_only"`
+	LimitOnly              bool    `json:"limit_only"`
+	CancelOnly             bool    `json:"cancel_only"`
+	Status                 string  `json:"status"`
+	StatusMessage          string  `json:"status_message"`
+	TradingDisabled        bool    `json:"trading_disabled"`
+	FxStablecoin           bool    `json:"fx_stablecoin"`
+	MaxSlippagePercentage  Decimal `json:"max_slippage_percentage"`
+	AuctionMode            bool    `json:"auction_mode"`
+	HighBidLimitPercentage Decimal `json:"high_bid_limit_percentage"`
 }
 
 type Granularity string
@@ -150,23 +281,17 @@ const (
 
 // GetProductCandles get rates for a single product by product ID, grouped in buckets.
 func (c *ApiClient) GetProductCandles(productId, start, end string, granularity Granularity) ([]ProductCandles, error) {
-	u := c.makeV3Url(fmt.Sprintf("/brokerage/products/%s/candles?start=%s&end=%s&granularity=%s", productId, start, end, granularity))
-
-	var data ProductCandlesData
-	resp, err := c.httpClient.Get(u)
-	if err != nil {
-		return data.Candles, err
-	}
+	return c.GetProductCandlesWithContext(context.Background(), productId, start, end, granularity)
+}
 
-	if !resp.IsSuccessState() {
-		return data.Candles, ErrFailedToUnmarshal
-	}
+// GetProductCandlesWithContext is like GetProductCandles but binds the request to the given context.
+func (c *ApiClient) GetProductCandlesWithContext(ctx context.Context, productId, start, end string, granularity Granularity) ([]ProductCandles, error) {
+	u := c.makeMarketUrl(fmt.Sprintf("/brokerage/products/%s/candles?start=%s&end=%s&granularity=%s", productId, start, end, granularity))
 
-	err = resp.Unmarshal(&data)
-	if err != nil {
+	var data ProductCandlesData
+	if err := c.get(ctx, u, &data); err != nil {
 		return data.Candles, err
 	}
-
 	return data.Candles, nil
 }
 
@@ -175,49 +300,62 @@ type ProductCandlesData struct {
 }
 
 type ProductCandles struct {
-	Start  string `json:"start"`
-	Low    string `json:"low"`
-	High   string `json:"high"`
-	Open   string `json:"open"`
-	Close  string `json:"close"`
-	Volume string `json:"volume"`
+	Start  time.Time `json:"start"`
+	Low    Decimal   `json:"low"`
+	High   Decimal   `json:"high"`
+	Open   Decimal   `json:"open"`
+	Close  Decimal   `json:"close"`
+	Volume Decimal   `json:"volume"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because Coinbase sends the start of a bucket as unix seconds.
+func (p *ProductCandles) UnmarshalJSON(data []byte) error {
+	type alias ProductCandles
+	aux := struct {
+		Start flexTime `json:"start"`
+		*alias
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Start = time.Time(aux.Start)
+	return nil
 }
 
 // GetMarketTrades get snapshot information, by product ID, about the last trades (ticks), best bid/ask, and 24h volume.
 func (c *ApiClient) GetMarketTrades(productId string, limit int32) (MarketTradesData, error) {
-	u := c.makeV3Url(fmt.Sprintf("/brokerage/products/%s/ticker?limit=%d", productId, limit))
+	return c.GetMarketTradesWithContext(context.Background(), productId, limit)
+}
+
+// GetMarketTradesWithContext is like GetMarketTrades but binds the request to the given context.
+func (c *ApiClient) GetMarketTradesWithContext(ctx context.Context, productId string, limit int32) (MarketTradesData, error) {
+	u := c.makeMarketUrl(fmt.Sprintf("/brokerage/products/%s/ticker?limit=%d", productId, limit))
 
 	var data MarketTradesData
-	resp, err := c.httpClient.Get(u)
-	if err != nil {
+	if err := c.get(ctx, u, &data); err != nil {
 		return data, err
 	}
-
-	if !resp.IsSuccessState() {
-		return data, ErrFailedToUnmarshal
-	}
-
-	err = resp.Unmarshal(&data)
-	if err != nil {
-		return MarketTradesData{}, err
-	}
-
 	return data, nil
 }
 
 type MarketTradesData struct {
 	Trades  []MarketTrade `json:"trades"`
-	BestBid string        `json:"best_bid"`
-	BestAsk string        `json:"best_ask"`
+	BestBid Decimal       `json:"best_bid"`
+	BestAsk Decimal       `json:"best_ask"`
 }
 
 // GetProductBook get a list of bids/asks for a single product. The amount of detail shown can be customized with the limit parameter.
 func (c *ApiClient) GetProductBook(productId string, limit int32) (ProductBookData, error) {
-	u := c.makeV3Url(fmt.Sprintf("/brokerage/product_book?product_id=%s&limit=%d", productId, limit))
+	return c.GetProductBookWithContext(context.Background(), productId, limit)
+}
+
+// GetProductBookWithContext is like GetProductBook but binds the request to the given context.
+func (c *ApiClient) GetProductBookWithContext(ctx context.Context, productId string, limit int32) (ProductBookData, error) {
+	u := c.makeMarketUrl(fmt.Sprintf("/brokerage/product_book?product_id=%s&limit=%d", productId, limit))
 
 	var data ProductBookData
-	if res, err := c.get(u, &data); err != nil {
-		return data, newResponseError(res)
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
 	}
 	return data, nil
 }
@@ -230,16 +368,35 @@ type PriceBook struct {
 	ProductId string           `json:"product_id"`
 	Bids      []PriceBookOrder `json:"bids"`
 	Asks      []PriceBookOrder `json:"asks"`
-	Time      string           `json:"time"`
+	Time      time.Time        `json:"time"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because the time is omitted or empty in some responses.
+func (p *PriceBook) UnmarshalJSON(data []byte) error {
+	type alias PriceBook
+	aux := struct {
+		Time flexTime `json:"time"`
+		*alias
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Time = time.Time(aux.Time)
+	return nil
 }
 
 type PriceBookOrder struct {
-	Price string `json:"price"`
-	Size  string `json:"size"`
+	Price Decimal `json:"price"`
+	Size  Decimal `json:"size"`
 }
 
 // GetBestBidAsk get the best bid/ask for all products. A subset of all products can be returned instead by using the product_ids input.
 func (c *ApiClient) GetBestBidAsk(productIds []string) (BestBidAskData, error) {
+	return c.GetBestBidAskWithContext(context.Background(), productIds)
+}
+
+// GetBestBidAskWithContext is like GetBestBidAsk but binds the request to the given context.
+func (c *ApiClient) GetBestBidAskWithContext(ctx context.Context, productIds []string) (BestBidAskData, error) {
 	query := strings.Join(productIds, "&product_ids=")
 	if query != "" {
 		query = "product_ids=" + query
@@ -247,10 +404,23 @@ func (c *ApiClient) GetBestBidAsk(productIds []string) (BestBidAskData, error) {
 		return BestBidAskData{}, fmt.Errorf("no product ids provided")
 	}
 
+	// there is no public best bid/ask endpoint, so without credentials the top of each product book is used instead
+	if c.public {
+		var data BestBidAskData
+		for _, id := range productIds {
+			book, err := c.GetProductBookWithContext(ctx, id, 1)
+			if err != nil {
+				return data, err
+			}
+			data.PriceBooks = append(data.PriceBooks, book.PriceBook)
+		}
+		return data, nil
+	}
+
 	u := c.makeV3Url(fmt.Sprintf("/brokerage/best_bid_ask?%s", query))
 	var data BestBidAskData
-	if res, err := c.get(u, &data); err != nil {
-		return data, newResponseError(res)
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
 	}
 	return data, nil
 }
@@ -1,10 +1,12 @@
 package coinbasev3
 
 import (
+	"context"
 	"fmt"
 	"github.com/jarcoal/httpmock"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestApiClient_GetBestBidAsk(t *testing.T) {
@@ -35,7 +37,7 @@ func TestApiClient_GetBestBidAsk(t *testing.T) {
 		}
 	}
 
-	askCheck := map[int]string{
+	askCheck := map[int]Decimal{
 		0: "2043.89",
 		1: "14.408",
 		2: "37685.29",
@@ -46,7 +48,7 @@ func TestApiClient_GetBestBidAsk(t *testing.T) {
 		}
 	}
 
-	bidCheck := map[int]string{
+	bidCheck := map[int]Decimal{
 		0: "2043.86",
 		1: "14.404",
 		2: "37683.15",
@@ -77,7 +79,7 @@ func TestApiClient_GetProductBook(t *testing.T) {
 		t.Fatalf("Expected no error, got %s", err)
 	}
 
-	checks := map[string]string{
+	checks := map[Decimal]Decimal{
 		"2056.29": "1.67746848",
 		"2056.34": "0.350161",
 		"2056.35": "3.16704916",
@@ -98,8 +100,8 @@ func TestApiClient_GetProductBook(t *testing.T) {
 		t.Errorf("Expected ETH-USD, got %s", data.PriceBook.ProductId)
 	}
 
-	if data.PriceBook.Time != "2023-11-28T16:56:43.770106Z" {
-		t.Errorf("Expected 2023-11-28T16:56:43.770106Z, got %s", data.PriceBook.Time)
+	if expected := time.Date(2023, 11, 28, 16, 56, 43, 770106000, time.UTC); !data.PriceBook.Time.Equal(expected) {
+		t.Errorf("Expected %s, got %s", expected, data.PriceBook.Time)
 	}
 }
 
@@ -206,3 +208,59 @@ func TestApiClient_GetProduct(t *testing.T) {
 		t.Errorf("Expected ETH, got %s", data.BaseCurrencyId)
 	}
 }
+
+func TestListProductsQuery_BuildQueryString(t *testing.T) {
+	q := ListProductsQuery{
+		Limit:                  50,
+		Offset:                 100,
+		ProductType:            ProductTypeFuture,
+		ProductIds:             []string{"BTC-USD", "ETH-USD"},
+		ContractExpiryType:     ContractExpiryTypeExpiring,
+		ExpiringContractStatus: ExpiringContractStatusAll,
+		GetAllProducts:         true,
+	}
+	expected := "?limit=50&offset=100&product_type=FUTURE&product_ids=BTC-USD&product_ids=ETH-USD&contract_expiry_type=EXPIRING&expiring_contract_status=STATUS_ALL&get_all_products=true"
+	if got := q.BuildQueryString(); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	if got := (ListProductsQuery{}).BuildQueryString(); got != "" {
+		t.Errorf("Expected empty query string, got %s", got)
+	}
+}
+
+func TestApiClient_ListProducts(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/products", "product_type=SPOT&product_ids=BTC-USD",
+		jsonResponder(`{"products":[{"product_id":"BTC-USD","price":"42000.01","base_increment":"0.00000001","product_type":"SPOT"}],"num_products":1}`))
+
+	data, err := api.ListProducts(ListProductsQuery{ProductType: ProductTypeSpot, ProductIds: []string{"BTC-USD"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if data.NumProducts != 1 || len(data.Products) != 1 {
+		t.Fatalf("Expected 1 product, got %+v", data)
+	}
+	if data.Products[0].ProductId != "BTC-USD" || data.Products[0].BaseIncrement.String() != "0.00000001" {
+		t.Errorf("Unexpected product %+v", data.Products[0])
+	}
+}
+
+func TestApiClient_NewProductsPager(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/products", "limit=2",
+		jsonResponder(`{"products":[{"product_id":"BTC-USD"},{"product_id":"ETH-USD"}],"num_products":3}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/products", "limit=2&offset=2",
+		jsonResponder(`{"products":[{"product_id":"SOL-USD"}],"num_products":3}`))
+
+	products, err := api.NewProductsPager(ListProductsQuery{Limit: 2}).All(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(products) != 3 || products[2].ProductId != "SOL-USD" {
+		t.Errorf("Unexpected products %+v", products)
+	}
+}
@@ -0,0 +1,88 @@
+package coinbasev3
+
+import (
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"testing"
+)
+
+func noAuthResponder(t *testing.T, body string) httpmock.Responder {
+	return func(request *http.Request) (*http.Response, error) {
+		for _, h := range []string{"Authorization", "CB-ACCESS-KEY", "CB-ACCESS-SIGN", "CB-ACCESS-TIMESTAMP"} {
+			if v := request.Header.Get(h); v != "" {
+				t.Errorf("Expected no %s header, got %s", h, v)
+			}
+		}
+		return jsonResponder(body)(request)
+	}
+}
+
+func TestPublicApiClient_GetProduct(t *testing.T) {
+	api := NewPublicApiClient()
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/market/products/BTC-USD",
+		noAuthResponder(t, `{"product_id":"BTC-USD","price":"42000.01","base_increment":"0.00000001"}`))
+
+	product, err := api.GetProduct("BTC-USD")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if product.ProductId != "BTC-USD" || product.Price.String() != "42000.01" {
+		t.Errorf("Unexpected product %+v", product)
+	}
+}
+
+func TestPublicApiClient_GetProductBook(t *testing.T) {
+	api := NewPublicApiClient()
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/market/product_book", "product_id=BTC-USD&limit=5",
+		noAuthResponder(t, `{"pricebook":{"product_id":"BTC-USD","bids":[{"price":"41999","size":"1.5"}],"asks":[{"price":"42001","size":"0.2"}]}}`))
+
+	data, err := api.GetProductBook("BTC-USD", 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(data.PriceBook.Bids) != 1 || data.PriceBook.Asks[0].Price.String() != "42001" {
+		t.Errorf("Unexpected price book %+v", data.PriceBook)
+	}
+}
+
+func TestPublicApiClient_GetBestBidAsk(t *testing.T) {
+	api := NewPublicApiClient()
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/market/product_book", "product_id=BTC-USD&limit=1",
+		noAuthResponder(t, `{"pricebook":{"product_id":"BTC-USD","bids":[{"price":"41999","size":"1.5"}],"asks":[{"price":"42001","size":"0.2"}]}}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/api/v3/brokerage/market/product_book", "product_id=ETH-USD&limit=1",
+		noAuthResponder(t, `{"pricebook":{"product_id":"ETH-USD","bids":[{"price":"2199","size":"3"}],"asks":[{"price":"2201","size":"4"}]}}`))
+
+	data, err := api.GetBestBidAsk([]string{"BTC-USD", "ETH-USD"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(data.PriceBooks) != 2 || data.PriceBooks[1].ProductId != "ETH-USD" {
+		t.Errorf("Unexpected price books %+v", data.PriceBooks)
+	}
+}
+
+func TestPublicApiClient_PrivateEndpoint(t *testing.T) {
+	api := NewPublicApiClient()
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterNoResponder(func(request *http.Request) (*http.Response, error) {
+		t.Errorf("Expected no request, got %s %s", request.Method, request.URL)
+		return httpmock.NewStringResponse(http.StatusInternalServerError, ""), nil
+	})
+	httpmock.ZeroCallCounters()
+
+	_, err := api.GetAccount("account-1")
+	if !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("Expected ErrNoCredentials, got %v", err)
+	}
+	if n := httpmock.GetTotalCallCount(); n != 0 {
+		t.Errorf("Expected no calls, got %d", n)
+	}
+}
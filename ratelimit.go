@@ -0,0 +1,285 @@
+package coinbasev3
+
+import (
+	"context"
+	"fmt"
+	"github.com/imroc/req/v3"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRateLimitExceeded = fmt.Errorf("client side rate limit exceeded")
+)
+
+const (
+	defaultPublicRate   = 10 // requests per second allowed on public endpoints
+	defaultPrivateRate  = 30 // requests per second allowed on private endpoints
+	defaultRateRetries  = 3
+	defaultRetryAfter   = 1 * time.Second
+	defaultMaxRateDelay = 1 * time.Minute
+)
+
+// RateLimitConfig configures the client side token buckets used by the ApiClient. Public and private endpoints are tracked in separate buckets because Coinbase limits them separately.
+type RateLimitConfig struct {
+	PublicRate    float64       // optional. requests per second for public endpoints. defaults to 10
+	PublicBurst   int           // optional. maximum burst for public endpoints. defaults to PublicRate rounded up, and at least 1
+	PrivateRate   float64       // optional. requests per second for private endpoints. defaults to 30
+	PrivateBurst  int           // optional. maximum burst for private endpoints. defaults to PrivateRate rounded up, and at least 1
+	FailFast      bool          // optional. defaults to false. return ErrRateLimitExceeded instead of waiting for a token
+	MaxRetries    int           // optional. number of times a 429 response is retried. defaults to 3, negative disables retries
+	MaxRetryAfter time.Duration // optional. caps the Retry-After duration that is honored. defaults to 1 minute
+}
+
+// NewRateLimitConfig returns a RateLimitConfig with the documented Coinbase REST limits.
+func NewRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		PublicRate:  defaultPublicRate,
+		PrivateRate: defaultPrivateRate,
+	}
+}
+
+// withDefaults fills in the zero values of the config.
+func (cfg RateLimitConfig) withDefaults() RateLimitConfig {
+	if cfg.PublicRate <= 0 {
+		cfg.PublicRate = defaultPublicRate
+	}
+	if cfg.PublicBurst <= 0 {
+		cfg.PublicBurst = defaultBurst(cfg.PublicRate)
+	}
+	if cfg.PrivateRate <= 0 {
+		cfg.PrivateRate = defaultPrivateRate
+	}
+	if cfg.PrivateBurst <= 0 {
+		cfg.PrivateBurst = defaultBurst(cfg.PrivateRate)
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultRateRetries
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.MaxRetryAfter <= 0 {
+		cfg.MaxRetryAfter = defaultMaxRateDelay
+	}
+	return cfg
+}
+
+// defaultBurst returns the burst for a rate. It is at least 1, so a rate below one request per second still lets an idle client send a request right away.
+func defaultBurst(rate float64) int {
+	burst := int(math.Ceil(rate))
+	if burst < 1 {
+		return 1
+	}
+	return burst
+}
+
+// SetRateLimit enables the client side rate limiter. Every request, including retries, takes a token from the public or private bucket before it is signed and sent. When a 429 is received the bucket is paused for the Retry-After duration and the request is retried up to MaxRetries times.
+func (c *ApiClient) SetRateLimit(cfg RateLimitConfig) {
+	c.limiter = newRateLimiter(cfg)
+	c.configureRetries()
+}
+
+// DisableRateLimit removes the client side rate limiter.
+func (c *ApiClient) DisableRateLimit() {
+	c.limiter = nil
+	c.configureRetries()
+}
+
+// rateLimiter holds a token bucket for public and one for private endpoints.
+type rateLimiter struct {
+	cfg     RateLimitConfig
+	public  *tokenBucket
+	private *tokenBucket
+}
+
+func newRateLimiter(cfg RateLimitConfig) *rateLimiter {
+	cfg = cfg.withDefaults()
+	return &rateLimiter{
+		cfg:     cfg,
+		public:  newTokenBucket(cfg.PublicRate, cfg.PublicBurst),
+		private: newTokenBucket(cfg.PrivateRate, cfg.PrivateBurst),
+	}
+}
+
+// bucket returns the bucket the request url counts against.
+func (l *rateLimiter) bucket(rawUrl string) *tokenBucket {
+	if isPublicEndpoint(rawUrl) {
+		return l.public
+	}
+	return l.private
+}
+
+// wait takes a token for the request, blocking until one is available unless the limiter is in fail fast mode.
+func (l *rateLimiter) wait(r *req.Request) error {
+	return l.bucket(r.RawURL).wait(r.Context(), l.cfg.FailFast)
+}
+
+// onResponse pauses the bucket of a throttled request for the Retry-After duration.
+func (l *rateLimiter) onResponse(resp *req.Response) {
+	if resp == nil || resp.Response == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+
+	d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		// no usable header, back off exponentially based on the attempt
+		d = defaultRetryAfter << uint(resp.Request.RetryAttempt)
+	}
+	if d > l.cfg.MaxRetryAfter {
+		d = l.cfg.MaxRetryAfter
+	}
+
+	l.bucket(resp.Request.RawURL).pause(d)
+}
+
+// publicExchangeHosts are the hosts of the unauthenticated Exchange market data API.
+var publicExchangeHosts = []string{"api.exchange.coinbase.com", "api-public.sandbox.exchange.coinbase.com"}
+
+// publicPaths are the paths of the unauthenticated market data and reference endpoints. A path matches itself and everything below it.
+var publicPaths = []string{"/api/v3/brokerage/market", "/v2/prices", "/v2/currencies", "/v2/exchange-rates", "/v2/time"}
+
+// isPublicEndpoint reports whether the url is one of the unauthenticated market data or reference endpoints. Besides picking the rate limit bucket, it decides whether a client without credentials may send the request.
+func isPublicEndpoint(rawUrl string) bool {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+
+	for _, host := range publicExchangeHosts {
+		if u.Hostname() == host {
+			return true
+		}
+	}
+
+	for _, p := range publicPaths {
+		if u.Path == p || strings.HasPrefix(u.Path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// tokenBucket is a thread safe token bucket that refills continuously at rate tokens per second.
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// refill adds the tokens accumulated since the last call. Must be called with the lock held.
+func (b *tokenBucket) refill(now time.Time) {
+	if now.Before(b.last) {
+		return
+	}
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// reserve takes a token and returns how long the caller has to wait before using it. In fail fast mode no token is taken when a wait would be required.
+func (b *tokenBucket) reserve(now time.Time, failFast bool) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+
+	var delay time.Duration
+	if b.pausedUntil.After(now) {
+		delay = b.pausedUntil.Sub(now)
+	}
+	if b.tokens < 1 {
+		delay += time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+
+	if delay > 0 && failFast {
+		return delay, false
+	}
+
+	b.tokens--
+	return delay, true
+}
+
+// cancel returns a reserved token that was not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// wait blocks until a token is available or the context is done.
+func (b *tokenBucket) wait(ctx context.Context, failFast bool) error {
+	delay, ok := b.reserve(time.Now(), failFast)
+	if !ok {
+		return ErrRateLimitExceeded
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// pause stops the bucket from handing out tokens for the given duration.
+func (b *tokenBucket) pause(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
@@ -0,0 +1,177 @@
+package coinbasev3
+
+import (
+	"context"
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_FailFast(t *testing.T) {
+	b := newTokenBucket(1, 1)
+
+	if err := b.wait(context.Background(), true); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if err := b.wait(context.Background(), true); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+}
+
+func TestTokenBucket_Blocking(t *testing.T) {
+	b := newTokenBucket(20, 1)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := b.wait(context.Background(), false); err != nil {
+			t.Fatalf("Expected no error, got %s", err)
+		}
+	}
+
+	// the first token is free, the next two take 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected to wait for tokens, took %s", elapsed)
+	}
+}
+
+func TestTokenBucket_ContextCanceled(t *testing.T) {
+	b := newTokenBucket(1, 1)
+	_ = b.wait(context.Background(), false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := b.wait(ctx, false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestTokenBucket_Pause(t *testing.T) {
+	b := newTokenBucket(100, 10)
+	b.pause(50 * time.Millisecond)
+
+	if err := b.wait(context.Background(), true); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected ErrRateLimitExceeded while paused, got %v", err)
+	}
+
+	start := time.Now()
+	if err := b.wait(context.Background(), false); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected to wait out the pause, took %s", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 11, 28, 16, 0, 0, 0, time.UTC)
+
+	d, ok := parseRetryAfter("3", now)
+	if !ok || d != 3*time.Second {
+		t.Errorf("Expected 3s, got %s", d)
+	}
+
+	d, ok = parseRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now)
+	if !ok || d != 5*time.Second {
+		t.Errorf("Expected 5s, got %s", d)
+	}
+
+	if _, ok = parseRetryAfter("soon", now); ok {
+		t.Errorf("Expected an invalid header to be rejected")
+	}
+}
+
+func TestIsPublicEndpoint(t *testing.T) {
+	checks := map[string]bool{
+		"https://api.coinbase.com/api/v3/brokerage/market/products/BTC-USD":   true,
+		"https://api.coinbase.com/v2/prices/BTC-USD/spot":                     true,
+		"https://api.exchange.coinbase.com/products":                          true,
+		"https://api.coinbase.com/api/v3/brokerage/products/BTC-USD":          false,
+		"https://api.coinbase.com/api/v3/brokerage/orders":                    false,
+		"https://api.coinbase.com/v2/currencies":                              true,
+		"https://api.coinbase.com/v2/time":                                    true,
+		"https://api.coinbase.com/v2/timeline":                                false,
+		"https://api.coinbase.com/v2/accounts/v2/time":                        false,
+		"https://api.coinbase.com/api/v3/brokerage/orders/brokerage/market/x": false,
+		"https://api.exchange.coinbase.com.evil.example/products":             false,
+	}
+
+	for u, want := range checks {
+		if isPublicEndpoint(u) != want {
+			t.Errorf("Expected %t for %s", want, u)
+		}
+	}
+}
+
+func TestApiClient_SetRateLimit_FailFast(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+	api.SetRateLimit(RateLimitConfig{PrivateRate: 1, FailFast: true})
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/0000", func(request *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(http.StatusOK, `{"order":{"order_id":"0000"}}`)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	if _, err := api.GetOrder("0000"); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if _, err := api.GetOrder("0000"); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+}
+
+func TestRateLimitConfig_FractionalRate(t *testing.T) {
+	cfg := RateLimitConfig{PublicRate: 0.5, PrivateRate: 2.5}.withDefaults()
+	if cfg.PublicBurst != 1 || cfg.PrivateBurst != 3 {
+		t.Fatalf("Expected bursts 1 and 3, got %d and %d", cfg.PublicBurst, cfg.PrivateBurst)
+	}
+
+	// an idle client gets its first request through immediately, even below one request per second
+	api := NewApiClient("api_key", "secret_key")
+	api.SetRateLimit(RateLimitConfig{PrivateRate: 0.2, FailFast: true})
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/0000",
+		jsonResponder(`{"order":{"order_id":"0000"}}`))
+
+	if _, err := api.GetOrder("0000"); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if _, err := api.GetOrder("0000"); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+}
+
+func TestApiClient_SetRateLimit_RetriesTooManyRequests(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+	api.SetRateLimit(NewRateLimitConfig())
+
+	calls := 0
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/0000", func(request *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			resp := httpmock.NewStringResponse(http.StatusTooManyRequests, `{"message":"too many requests"}`)
+			resp.Header.Set("Retry-After", "0")
+			return resp, nil
+		}
+		resp := httpmock.NewStringResponse(http.StatusOK, `{"order":{"order_id":"0000"}}`)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	data, err := api.GetOrder("0000")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if data.OrderId != "0000" {
+		t.Errorf("Expected 0000, got %s", data.OrderId)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
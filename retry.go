@@ -0,0 +1,215 @@
+package coinbasev3
+
+import (
+	"context"
+	"errors"
+	"github.com/imroc/req/v3"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how failed requests are retried. Requests with idempotent methods (GET, HEAD, PUT, DELETE) are always eligible. POST requests are only retried when the endpoint is safe to repeat, e.g. CreateOrder with a ClientOrderID, which Coinbase uses to de-duplicate orders.
+type RetryPolicy struct {
+	MaxRetries           int           // number of retries after the first attempt. zero disables retries
+	MinBackoff           time.Duration // optional. backoff before the first retry. defaults to 1 second
+	MaxBackoff           time.Duration // optional. upper bound of the backoff. defaults to 5 seconds
+	RetryableStatusCodes []int         // optional. response status codes that are retried. defaults to 429, 500, 502, 503 and 504
+	RetryNetworkErrors   bool          // retry when the request fails without a response, e.g. connection resets or timeouts
+	MaxRetryAfter        time.Duration // optional. caps the Retry-After duration that is honored. defaults to 1 minute
+}
+
+// NewRetryPolicy returns the default retry policy: 3 retries with a jittered exponential backoff between 1 and 5 seconds on network errors and 429/5xx responses.
+func NewRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:           3,
+		MinBackoff:           1 * time.Second,
+		MaxBackoff:           5 * time.Second,
+		RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		RetryNetworkErrors:   true,
+		MaxRetryAfter:        defaultMaxRateDelay,
+	}
+}
+
+// withDefaults fills in the zero values of the policy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	def := NewRetryPolicy()
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = def.MinBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = def.MaxBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = p.MinBackoff
+	}
+	if p.RetryableStatusCodes == nil {
+		p.RetryableStatusCodes = def.RetryableStatusCodes
+	}
+	if p.MaxRetries < 0 {
+		p.MaxRetries = 0
+	}
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = def.MaxRetryAfter
+	}
+	return p
+}
+
+// shouldRetry reports whether the policy allows another attempt of the request. A request is not retried once its context is done, or when its deadline would pass during the backoff.
+func (p RetryPolicy) shouldRetry(resp *req.Response, err error) bool {
+	if resp == nil || resp.Request == nil || resp.Request.RetryAttempt >= p.MaxRetries {
+		return false
+	}
+	if !isRetrySafe(resp.Request) {
+		return false
+	}
+
+	ctx := resp.Request.Context()
+	if ctx.Err() != nil {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < p.minBackoff(resp, resp.Request.RetryAttempt+1) {
+		return false
+	}
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrNoCredentials) {
+			return false
+		}
+		return p.RetryNetworkErrors
+	}
+
+	for _, code := range p.RetryableStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the jittered exponential backoff before the given retry attempt, starting at 1. A Retry-After header on the response takes precedence, capped at MaxRetryAfter.
+func (p RetryPolicy) backoff(resp *req.Response, attempt int) time.Duration {
+	if d, ok := p.retryAfter(resp); ok {
+		return d
+	}
+
+	d := p.exponentialBackoff(attempt)
+	// equal jitter: wait at least half of the backoff
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + rand.Int63n(half))
+}
+
+// minBackoff returns the shortest wait backoff can return for the given retry attempt.
+func (p RetryPolicy) minBackoff(resp *req.Response, attempt int) time.Duration {
+	if d, ok := p.retryAfter(resp); ok {
+		return d
+	}
+
+	d := p.exponentialBackoff(attempt)
+	if d/2 <= 0 {
+		return d
+	}
+	return d / 2
+}
+
+// exponentialBackoff returns the backoff before the given retry attempt without jitter.
+func (p RetryPolicy) exponentialBackoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d = calculateBackoff(d, p.MaxBackoff)
+	}
+	return d
+}
+
+// retryAfter returns the Retry-After duration of the response, capped at MaxRetryAfter.
+func (p RetryPolicy) retryAfter(resp *req.Response) (time.Duration, bool) {
+	if resp == nil || resp.Response == nil {
+		return 0, false
+	}
+	d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return 0, false
+	}
+	if d > p.MaxRetryAfter {
+		d = p.MaxRetryAfter
+	}
+	return d, true
+}
+
+// SetRetryPolicy replaces the retry policy of the client. NewApiClient starts with NewRetryPolicy.
+func (c *ApiClient) SetRetryPolicy(p RetryPolicy) {
+	p = p.withDefaults()
+	c.retry = &p
+	c.configureRetries()
+}
+
+// DisableRetries turns off retries. Throttled requests are still retried if a rate limit is set.
+func (c *ApiClient) DisableRetries() {
+	c.retry = nil
+	c.configureRetries()
+}
+
+// configureRetries applies the retry policy and the retry behaviour of the rate limiter to the underlying req client.
+func (c *ApiClient) configureRetries() {
+	limiterRetries := 0
+	if c.limiter != nil && !c.limiter.cfg.FailFast {
+		limiterRetries = c.limiter.cfg.MaxRetries
+	}
+	policyRetries := 0
+	if c.retry != nil {
+		policyRetries = c.retry.MaxRetries
+	}
+
+	count := policyRetries
+	if limiterRetries > count {
+		count = limiterRetries
+	}
+	c.client.SetCommonRetryCount(count)
+	if count == 0 {
+		return
+	}
+
+	c.client.SetCommonRetryCondition(func(resp *req.Response, err error) bool {
+		if resp != nil && resp.Request != nil && resp.Request.Context().Err() != nil {
+			return false
+		}
+		// a throttled request was not processed, so it is safe to repeat regardless of the method
+		if limiterRetries > 0 && err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			return resp.Request.RetryAttempt < limiterRetries
+		}
+		return c.retry != nil && c.retry.shouldRetry(resp, err)
+	})
+	c.client.SetCommonRetryInterval(func(resp *req.Response, attempt int) time.Duration {
+		// the limiter already waits out the Retry-After pause before the retry is signed and sent
+		if resp == nil {
+			return 0
+		}
+		if limiterRetries > 0 && resp.Err == nil && resp.Response != nil && resp.StatusCode == http.StatusTooManyRequests {
+			return 0
+		}
+		if c.retry == nil {
+			return 0
+		}
+		return c.retry.backoff(resp, attempt)
+	})
+}
+
+type retrySafeKey struct{}
+
+// withRetrySafe marks the requests made with the context as safe to retry even if they are not idempotent by method.
+func withRetrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retrySafeKey{}, true)
+}
+
+// isRetrySafe reports whether repeating the request cannot cause a duplicate side effect. POST requests that move funds or create resources, e.g. portfolio transfers, sweeps and deposits, are never retried unless Coinbase de-duplicates them, since a repeated request would act twice.
+func isRetrySafe(r *req.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	safe, _ := r.Context().Value(retrySafeKey{}).(bool)
+	return safe
+}
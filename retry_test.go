@@ -0,0 +1,138 @@
+package coinbasev3
+
+import (
+	"context"
+	"github.com/imroc/req/v3"
+	"github.com/jarcoal/httpmock"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newRetryTestClient() *ApiClient {
+	api := NewApiClient("api_key", "secret_key")
+	api.SetRetryPolicy(RetryPolicy{
+		MaxRetries:           2,
+		MinBackoff:           time.Millisecond,
+		MaxBackoff:           2 * time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	})
+	return api
+}
+
+func TestRetryPolicy_RetriesGet(t *testing.T) {
+	api := newRetryTestClient()
+
+	calls := 0
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/0000", func(request *http.Request) (*http.Response, error) {
+		calls++
+		if calls < 3 {
+			return httpmock.NewStringResponse(http.StatusServiceUnavailable, `{"message":"unavailable"}`), nil
+		}
+		resp := httpmock.NewStringResponse(http.StatusOK, `{"order":{"order_id":"0000"}}`)
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	})
+
+	data, err := api.GetOrder("0000")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if data.OrderId != "0000" {
+		t.Errorf("Expected 0000, got %s", data.OrderId)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_CreateOrder(t *testing.T) {
+	api := newRetryTestClient()
+
+	calls := 0
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/api/v3/brokerage/orders", func(request *http.Request) (*http.Response, error) {
+		calls++
+		return httpmock.NewStringResponse(http.StatusServiceUnavailable, `{"message":"unavailable"}`), nil
+	})
+
+	// without a client order id a retry could place a second order
+	_, err := api.CreateOrder(CreateOrderRequest{ProductID: "BTC-USD", Side: "BUY"})
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+
+	calls = 0
+	_, err = api.CreateOrder(CreateOrderRequest{ClientOrderID: "0000", ProductID: "BTC-USD", Side: "BUY"})
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestApiClient_DisableRetries(t *testing.T) {
+	api := newRetryTestClient()
+	api.DisableRetries()
+
+	calls := 0
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/orders/historical/0000", func(request *http.Request) (*http.Response, error) {
+		calls++
+		return httpmock.NewStringResponse(http.StatusServiceUnavailable, `{"message":"unavailable"}`), nil
+	})
+
+	if _, err := api.GetOrder("0000"); err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_RetryAfterDeadline(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	calls := 0
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/api/v3/brokerage/products/BTC-USD", func(request *http.Request) (*http.Response, error) {
+		calls++
+		resp := httpmock.NewStringResponse(http.StatusServiceUnavailable, `{"message":"unavailable"}`)
+		resp.Header.Set("Retry-After", "3600")
+		return resp, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := api.GetProductWithContext(ctx, "BTC-USD"); err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the call to return before the deadline, took %s", elapsed)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_MaxRetryAfter(t *testing.T) {
+	p := RetryPolicy{MaxRetryAfter: 10 * time.Second}.withDefaults()
+
+	resp := &req.Response{Response: &http.Response{Header: http.Header{}}}
+	resp.Header.Set("Retry-After", "3600")
+	if d := p.backoff(resp, 1); d != 10*time.Second {
+		t.Errorf("Expected 10s, got %s", d)
+	}
+
+	resp.Header.Set("Retry-After", "2")
+	if d := p.backoff(resp, 1); d != 2*time.Second {
+		t.Errorf("Expected 2s, got %s", d)
+	}
+}
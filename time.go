@@ -1,19 +1,27 @@
 package coinbasev3
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // GetServerTime get the API server time.
 func (c *ApiClient) GetServerTime() (ServerTime, error) {
+	return c.GetServerTimeWithContext(context.Background())
+}
+
+// GetServerTimeWithContext is like GetServerTime but binds the request to the given context.
+func (c *ApiClient) GetServerTimeWithContext(ctx context.Context) (ServerTime, error) {
 	u := "https://api.coinbase.com/v2/time"
 
 	var servTime ServerTime
-	resp, err := c.client.R().SetSuccessResult(&servTime).Get(u)
+	resp, err := c.client.R().SetContext(ctx).SetSuccessResult(&servTime).Get(u)
 	if err != nil {
 		return servTime, err
 	}
 
 	if !resp.IsSuccessState() {
-		return servTime, ErrFailedToUnmarshal
+		return servTime, newResponseError(resp)
 	}
 
 	return servTime, nil
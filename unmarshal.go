@@ -0,0 +1,83 @@
+package coinbasev3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidTime = fmt.Errorf("invalid time")
+	ErrInvalidInt  = fmt.Errorf("invalid integer")
+)
+
+// flexTime decodes the time formats Coinbase sends: RFC 3339 strings, unix seconds as a string or a number, and null or an empty string for an unset time.
+type flexTime time.Time
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *flexTime) UnmarshalJSON(data []byte) error {
+	s, err := unquoteFlex(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidTime, string(data))
+	}
+	parsed, err := parseFlexTime(s)
+	if err != nil {
+		return err
+	}
+	*t = flexTime(parsed)
+	return nil
+}
+
+// parseFlexTime parses an RFC 3339 time or unix seconds. An empty string is the zero time.
+func parseFlexTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTime, s)
+	}
+	return t, nil
+}
+
+// flexInt decodes integers sent as JSON numbers or strings. Null and an empty string decode to zero.
+type flexInt int
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (i *flexInt) UnmarshalJSON(data []byte) error {
+	s, err := unquoteFlex(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidInt, string(data))
+	}
+	if s == "" {
+		*i = 0
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidInt, s)
+	}
+	*i = flexInt(n)
+	return nil
+}
+
+// unquoteFlex returns the contents of a JSON string, the literal text of any other value, or an empty string for null.
+func unquoteFlex(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return "", nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	return string(data), nil
+}
@@ -0,0 +1,101 @@
+package coinbasev3
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFlexTime_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{`"2023-11-28T16:56:43.770106Z"`, time.Date(2023, 11, 28, 16, 56, 43, 770106000, time.UTC)},
+		{`"1609459200"`, time.Unix(1609459200, 0)},
+		{`1609459200`, time.Unix(1609459200, 0)},
+		{`""`, time.Time{}},
+		{`null`, time.Time{}},
+	}
+	for _, tt := range tests {
+		var ft flexTime
+		if err := json.Unmarshal([]byte(tt.input), &ft); err != nil {
+			t.Fatalf("Expected no error for %s, got %s", tt.input, err)
+		}
+		if got := time.Time(ft); !got.Equal(tt.expected) {
+			t.Errorf("Expected %s for %s, got %s", tt.expected, tt.input, got)
+		}
+	}
+
+	var ft flexTime
+	if err := json.Unmarshal([]byte(`"yesterday"`), &ft); !errors.Is(err, ErrInvalidTime) {
+		t.Errorf("Expected ErrInvalidTime, got %v", err)
+	}
+}
+
+func TestFlexInt_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{`"4"`, 4},
+		{`4`, 4},
+		{`""`, 0},
+		{`null`, 0},
+	}
+	for _, tt := range tests {
+		var fi flexInt
+		if err := json.Unmarshal([]byte(tt.input), &fi); err != nil {
+			t.Fatalf("Expected no error for %s, got %s", tt.input, err)
+		}
+		if int(fi) != tt.expected {
+			t.Errorf("Expected %d for %s, got %d", tt.expected, tt.input, fi)
+		}
+	}
+
+	var fi flexInt
+	if err := json.Unmarshal([]byte(`"four"`), &fi); !errors.Is(err, ErrInvalidInt) {
+		t.Errorf("Expected ErrInvalidInt, got %v", err)
+	}
+}
+
+func TestProductCandles_UnmarshalJSON(t *testing.T) {
+	var candle ProductCandles
+	if err := json.Unmarshal([]byte(`{"start":"1609459200","low":"715.22","high":"732","open":"730.97","close":"721.8","volume":"18729.1"}`), &candle); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !candle.Start.Equal(time.Unix(1609459200, 0)) || candle.Close.String() != "721.8" {
+		t.Errorf("Unexpected candle %+v", candle)
+	}
+}
+
+func TestEmptyTimes_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input  string
+		target interface{}
+	}{
+		{`{"applied_incentive":false,"ends_at":""}`, &ConvertTradeIncentiveInfo{}},
+		{`{"product_id":"BIT-28JUL23-CDE","expiration_time":""}`, &FuturesPosition{}},
+		{`{"id":"s-1","scheduled_time":""}`, &FuturesSweep{}},
+		{`{"margin_window":{"margin_window_type":"FCM_MARGIN_WINDOW_TYPE_OVERNIGHT","end_time":""}}`, &CurrentMarginWindowData{}},
+		{`{"limit_limit_gtd":{"base_size":"1","limit_price":"100","end_time":""}}`, &OrderConfiguration{}},
+		{`{"stop_limit_stop_limit_gtd":{"base_size":"1","limit_price":"100","stop_price":"99","end_time":""}}`, &OrderConfiguration{}},
+		{`{"trigger_bracket_gtd":{"base_size":"1","limit_price":"100","stop_trigger_price":"90","end_time":""}}`, &OrderConfiguration{}},
+		{`{"twap_limit_gtd":{"base_size":"1","limit_price":"100","start_time":"","end_time":""}}`, &OrderConfiguration{}},
+		{`{"id":"d-1","status":"created","payout_at":""}`, &WalletTransfer{}},
+	}
+	for _, tt := range tests {
+		if err := json.Unmarshal([]byte(tt.input), tt.target); err != nil {
+			t.Errorf("Expected no error for %s, got %s", tt.input, err)
+		}
+	}
+
+	var cfg OrderConfiguration
+	if err := json.Unmarshal([]byte(`{"limit_limit_gtd":{"base_size":"1","limit_price":"100","end_time":"2024-01-01T00:00:00Z"}}`), &cfg); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !cfg.LimitLimitGtd.EndTime.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || cfg.LimitLimitGtd.BaseSize != "1" {
+		t.Errorf("Unexpected configuration %+v", cfg.LimitLimitGtd)
+	}
+}
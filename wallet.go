@@ -0,0 +1,355 @@
+package coinbasev3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type WalletTransactionType string
+
+const (
+	WalletTransactionTypeSend               WalletTransactionType = "send"
+	WalletTransactionTypeRequest            WalletTransactionType = "request"
+	WalletTransactionTypeTransfer           WalletTransactionType = "transfer"
+	WalletTransactionTypeBuy                WalletTransactionType = "buy"
+	WalletTransactionTypeSell               WalletTransactionType = "sell"
+	WalletTransactionTypeFiatDeposit        WalletTransactionType = "fiat_deposit"
+	WalletTransactionTypeFiatWithdrawal     WalletTransactionType = "fiat_withdrawal"
+	WalletTransactionTypeExchangeDeposit    WalletTransactionType = "exchange_deposit"
+	WalletTransactionTypeExchangeWithdrawal WalletTransactionType = "exchange_withdrawal"
+	WalletTransactionTypeVaultWithdrawal    WalletTransactionType = "vault_withdrawal"
+	WalletTransactionTypeAdvancedTradeFill  WalletTransactionType = "advanced_trade_fill"
+	WalletTransactionTypeTrade              WalletTransactionType = "trade"
+	WalletTransactionTypeStakingReward      WalletTransactionType = "staking_reward"
+	WalletTransactionTypeInterest           WalletTransactionType = "interest"
+)
+
+type WalletTransactionStatus string
+
+const (
+	WalletTransactionStatusPending             WalletTransactionStatus = "pending"
+	WalletTransactionStatusCompleted           WalletTransactionStatus = "completed"
+	WalletTransactionStatusFailed              WalletTransactionStatus = "failed"
+	WalletTransactionStatusExpired             WalletTransactionStatus = "expired"
+	WalletTransactionStatusCanceled            WalletTransactionStatus = "canceled"
+	WalletTransactionStatusWaitingForSignature WalletTransactionStatus = "waiting_for_signature"
+	WalletTransactionStatusWaitingForClearing  WalletTransactionStatus = "waiting_for_clearing"
+)
+
+// WalletMoney is an amount in a given currency, as returned by the v2 API.
+type WalletMoney struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// WalletPagination is the pagination block of a v2 list response.
+type WalletPagination struct {
+	EndingBefore         string `json:"ending_before"`
+	StartingAfter        string `json:"starting_after"`
+	PreviousEndingBefore string `json:"previous_ending_before"`
+	NextStartingAfter    string `json:"next_starting_after"`
+	Limit                int    `json:"limit"`
+	Order                string `json:"order"`
+	PreviousUri          string `json:"previous_uri"`
+	NextUri              string `json:"next_uri"`
+}
+
+// HasNext reports whether there is a page after this one.
+func (p WalletPagination) HasNext() bool {
+	return p.NextUri != ""
+}
+
+// WalletPaginationQuery represents the pagination parameters of the v2 list endpoints.
+type WalletPaginationQuery struct {
+	// Limit int Number of results per page. Defaults to 25, the maximum is 100.
+	Limit int `json:"limit"`
+	// Order string Result order, asc or desc. Defaults to desc.
+	Order string `json:"order"`
+	// StartingAfter string Id of the resource to start after, for the next page.
+	StartingAfter string `json:"starting_after"`
+	// EndingBefore string Id of the resource to end before, for the previous page.
+	EndingBefore string `json:"ending_before"`
+}
+
+// BuildQueryString creates a query string from the request parameters. If no parameters are set, an empty string is returned.
+func (q WalletPaginationQuery) BuildQueryString() string {
+	sb := strings.Builder{}
+	if q.Limit > 0 {
+		sb.WriteString(fmt.Sprintf("&limit=%d", q.Limit))
+	}
+	if q.Order != "" {
+		sb.WriteString(fmt.Sprintf("&order=%s", q.Order))
+	}
+	if q.StartingAfter != "" {
+		sb.WriteString(fmt.Sprintf("&starting_after=%s", q.StartingAfter))
+	}
+	if q.EndingBefore != "" {
+		sb.WriteString(fmt.Sprintf("&ending_before=%s", q.EndingBefore))
+	}
+
+	if sb.Len() > 0 {
+		return "?" + sb.String()[1:]
+	}
+	return ""
+}
+
+type WalletAccountData struct {
+	Data WalletAccount `json:"data"`
+}
+
+type WalletAccount struct {
+	Id               string         `json:"id"`
+	Name             string         `json:"name"`
+	Primary          bool           `json:"primary"`
+	Type             string         `json:"type"`
+	Currency         WalletCurrency `json:"currency"`
+	Balance          WalletMoney    `json:"balance"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	Resource         string         `json:"resource"`
+	ResourcePath     string         `json:"resource_path"`
+	AllowDeposits    bool           `json:"allow_deposits"`
+	AllowWithdrawals bool           `json:"allow_withdrawals"`
+}
+
+type WalletCurrency struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Color        string `json:"color"`
+	SortIndex    int    `json:"sort_index"`
+	Exponent     int    `json:"exponent"`
+	Type         string `json:"type"`
+	AddressRegex string `json:"address_regex"`
+	AssetId      string `json:"asset_id"`
+	Slug         string `json:"slug"`
+}
+
+// WalletListAccounts get a page of the user's v2 wallet accounts.
+func (c *ApiClient) WalletListAccounts(q WalletPaginationQuery) (WalletListAccountsData, error) {
+	return c.WalletListAccountsWithContext(context.Background(), q)
+}
+
+// WalletListAccountsWithContext is like WalletListAccounts but binds the request to the given context.
+func (c *ApiClient) WalletListAccountsWithContext(ctx context.Context, q WalletPaginationQuery) (WalletListAccountsData, error) {
+	u := c.makeV2Url(fmt.Sprintf("/accounts%s", q.BuildQueryString()))
+
+	var data WalletListAccountsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type WalletListAccountsData struct {
+	Pagination WalletPagination `json:"pagination"`
+	Data       []WalletAccount  `json:"data"`
+}
+
+// WalletGetAccount get a v2 wallet account, given its id or currency code.
+func (c *ApiClient) WalletGetAccount(accountId string) (WalletAccount, error) {
+	return c.WalletGetAccountWithContext(context.Background(), accountId)
+}
+
+// WalletGetAccountWithContext is like WalletGetAccount but binds the request to the given context.
+func (c *ApiClient) WalletGetAccountWithContext(ctx context.Context, accountId string) (WalletAccount, error) {
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s", accountId))
+
+	var data WalletAccountData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Data, err
+	}
+	return data.Data, nil
+}
+
+type WalletTransactionData struct {
+	Data WalletTransaction `json:"data"`
+}
+
+type WalletTransaction struct {
+	Id              string                  `json:"id"`
+	Type            WalletTransactionType   `json:"type"`
+	Status          WalletTransactionStatus `json:"status"`
+	Amount          WalletMoney             `json:"amount"`
+	NativeAmount    WalletMoney             `json:"native_amount"`
+	Description     string                  `json:"description"`
+	CreatedAt       time.Time               `json:"created_at"`
+	UpdatedAt       time.Time               `json:"updated_at"`
+	Resource        string                  `json:"resource"`
+	ResourcePath    string                  `json:"resource_path"`
+	InstantExchange bool                    `json:"instant_exchange"`
+	Idem            string                  `json:"idem"`
+	Network         WalletNetwork           `json:"network"`
+	To              WalletParty             `json:"to"`
+	From            WalletParty             `json:"from"`
+	Address         WalletResource          `json:"address"`
+	Buy             WalletResource          `json:"buy"`
+	Sell            WalletResource          `json:"sell"`
+	Details         struct {
+		Title    string `json:"title"`
+		Subtitle string `json:"subtitle"`
+		Header   string `json:"header"`
+		Health   string `json:"health"`
+	} `json:"details"`
+}
+
+type WalletNetwork struct {
+	Status         string      `json:"status"`
+	Hash           string      `json:"hash"`
+	TransactionUrl string      `json:"transaction_url"`
+	Name           string      `json:"name"`
+	TransactionFee WalletMoney `json:"transaction_fee"`
+	Confirmations  int         `json:"confirmations"`
+}
+
+// WalletParty is the sender or recipient of a transaction: a Coinbase user, account or email, or a crypto address.
+type WalletParty struct {
+	Id           string `json:"id"`
+	Resource     string `json:"resource"`
+	ResourcePath string `json:"resource_path"`
+	Address      string `json:"address"`
+	Currency     string `json:"currency"`
+	Email        string `json:"email"`
+}
+
+// WalletResource is a reference to another v2 resource.
+type WalletResource struct {
+	Id           string `json:"id"`
+	Resource     string `json:"resource"`
+	ResourcePath string `json:"resource_path"`
+}
+
+// WalletListTransactions get a page of the transactions of a v2 wallet account.
+func (c *ApiClient) WalletListTransactions(accountId string, q WalletPaginationQuery) (WalletListTransactionsData, error) {
+	return c.WalletListTransactionsWithContext(context.Background(), accountId, q)
+}
+
+// WalletListTransactionsWithContext is like WalletListTransactions but binds the request to the given context.
+func (c *ApiClient) WalletListTransactionsWithContext(ctx context.Context, accountId string, q WalletPaginationQuery) (WalletListTransactionsData, error) {
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s/transactions%s", accountId, q.BuildQueryString()))
+
+	var data WalletListTransactionsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type WalletListTransactionsData struct {
+	Pagination WalletPagination    `json:"pagination"`
+	Data       []WalletTransaction `json:"data"`
+}
+
+// WalletGetTransaction get a transaction of a v2 wallet account.
+func (c *ApiClient) WalletGetTransaction(accountId, transactionId string) (WalletTransaction, error) {
+	return c.WalletGetTransactionWithContext(context.Background(), accountId, transactionId)
+}
+
+// WalletGetTransactionWithContext is like WalletGetTransaction but binds the request to the given context.
+func (c *ApiClient) WalletGetTransactionWithContext(ctx context.Context, accountId, transactionId string) (WalletTransaction, error) {
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s/transactions/%s", accountId, transactionId))
+
+	var data WalletTransactionData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Data, err
+	}
+	return data.Data, nil
+}
+
+type WalletAddressData struct {
+	Data WalletAddress `json:"data"`
+}
+
+type WalletAddress struct {
+	Id          string `json:"id"`
+	Address     string `json:"address"`
+	AddressInfo struct {
+		Address        string `json:"address"`
+		DestinationTag string `json:"destination_tag"`
+	} `json:"address_info"`
+	Name         string    `json:"name"`
+	Network      string    `json:"network"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Resource     string    `json:"resource"`
+	ResourcePath string    `json:"resource_path"`
+	DepositUri   string    `json:"deposit_uri"`
+}
+
+// WalletListAddresses get a page of the deposit addresses of a v2 wallet account.
+func (c *ApiClient) WalletListAddresses(accountId string, q WalletPaginationQuery) (WalletListAddressesData, error) {
+	return c.WalletListAddressesWithContext(context.Background(), accountId, q)
+}
+
+// WalletListAddressesWithContext is like WalletListAddresses but binds the request to the given context.
+func (c *ApiClient) WalletListAddressesWithContext(ctx context.Context, accountId string, q WalletPaginationQuery) (WalletListAddressesData, error) {
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s/addresses%s", accountId, q.BuildQueryString()))
+
+	var data WalletListAddressesData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type WalletListAddressesData struct {
+	Pagination WalletPagination `json:"pagination"`
+	Data       []WalletAddress  `json:"data"`
+}
+
+// WalletGetAddress get a deposit address of a v2 wallet account, given its id or the address itself.
+func (c *ApiClient) WalletGetAddress(accountId, addressId string) (WalletAddress, error) {
+	return c.WalletGetAddressWithContext(context.Background(), accountId, addressId)
+}
+
+// WalletGetAddressWithContext is like WalletGetAddress but binds the request to the given context.
+func (c *ApiClient) WalletGetAddressWithContext(ctx context.Context, accountId, addressId string) (WalletAddress, error) {
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s/addresses/%s", accountId, addressId))
+
+	var data WalletAddressData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Data, err
+	}
+	return data.Data, nil
+}
+
+// WalletCreateAddress create a new deposit address for a v2 wallet account. The name is optional.
+func (c *ApiClient) WalletCreateAddress(accountId, name string) (WalletAddress, error) {
+	return c.WalletCreateAddressWithContext(context.Background(), accountId, name)
+}
+
+// WalletCreateAddressWithContext is like WalletCreateAddress but binds the request to the given context.
+func (c *ApiClient) WalletCreateAddressWithContext(ctx context.Context, accountId, name string) (WalletAddress, error) {
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s/addresses", accountId))
+
+	var data WalletAddressData
+	body, err := json.Marshal(struct {
+		Name string `json:"name,omitempty"`
+	}{name})
+	if err != nil {
+		return data.Data, err
+	}
+
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data.Data, err
+	}
+	return data.Data, nil
+}
+
+// WalletListAddressTransactions get a page of the transactions sent to a deposit address, e.g. to reconcile on-chain deposits.
+func (c *ApiClient) WalletListAddressTransactions(accountId, addressId string, q WalletPaginationQuery) (WalletListTransactionsData, error) {
+	return c.WalletListAddressTransactionsWithContext(context.Background(), accountId, addressId, q)
+}
+
+// WalletListAddressTransactionsWithContext is like WalletListAddressTransactions but binds the request to the given context.
+func (c *ApiClient) WalletListAddressTransactionsWithContext(ctx context.Context, accountId, addressId string, q WalletPaginationQuery) (WalletListTransactionsData, error) {
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s/addresses/%s/transactions%s", accountId, addressId, q.BuildQueryString()))
+
+	var data WalletListTransactionsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
@@ -0,0 +1,116 @@
+package coinbasev3
+
+import (
+	"context"
+	"github.com/jarcoal/httpmock"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestApiClient_WalletAccounts(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	account := `{"id":"w-btc","name":"BTC Wallet","primary":true,"type":"wallet","currency":{"code":"BTC","name":"Bitcoin","exponent":8,"type":"crypto"},"balance":{"amount":"0.50000000","currency":"BTC"},"created_at":"2021-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z","resource":"account","resource_path":"/v2/accounts/w-btc","allow_deposits":true,"allow_withdrawals":true}`
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/v2/accounts", "limit=1",
+		jsonResponder(`{"pagination":{"ending_before":null,"starting_after":null,"limit":1,"order":"desc","previous_uri":null,"next_uri":"/v2/accounts?limit=1&starting_after=w-btc","next_starting_after":"w-btc"},"data":[`+account+`]}`))
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/v2/accounts", "limit=1&starting_after=w-btc",
+		jsonResponder(`{"pagination":{"limit":1,"order":"desc","next_uri":null},"data":[{"id":"w-usd","name":"USD Wallet","type":"fiat","currency":{"code":"USD"},"balance":{"amount":"10.00","currency":"USD"}}]}`))
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/v2/accounts/w-btc", jsonResponder(`{"data":`+account+`}`))
+
+	page, err := api.WalletListAccounts(WalletPaginationQuery{Limit: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !page.Pagination.HasNext() || page.Pagination.NextStartingAfter != "w-btc" || len(page.Data) != 1 {
+		t.Fatalf("Unexpected page %+v", page)
+	}
+
+	accounts, err := api.NewWalletAccountsPager(WalletPaginationQuery{Limit: 1}).All(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if len(accounts) != 2 || accounts[1].Id != "w-usd" {
+		t.Fatalf("Expected both pages to be fetched, got %+v", accounts)
+	}
+
+	a, err := api.WalletGetAccount("w-btc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if a.Currency.Code != "BTC" || !a.Balance.Amount.Equal("0.5") || a.Currency.Exponent != 8 {
+		t.Errorf("Unexpected account %+v", a)
+	}
+}
+
+func TestApiClient_WalletTransactions(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	tx := `{"id":"t-1","type":"send","status":"completed","amount":{"amount":"0.01","currency":"BTC"},"native_amount":{"amount":"650.00","currency":"USD"},"description":null,"created_at":"2024-05-01T12:00:00Z","updated_at":"2024-05-01T12:30:00Z","resource":"transaction","resource_path":"/v2/accounts/w-btc/transactions/t-1","instant_exchange":false,"network":{"status":"confirmed","hash":"abc123","transaction_fee":{"amount":"0.0001","currency":"BTC"},"confirmations":6},"from":{"resource":"bitcoin_network","currency":"BTC"},"address":{"id":"addr-1","resource":"address","resource_path":"/v2/accounts/w-btc/addresses/addr-1"},"details":{"title":"Received Bitcoin","subtitle":"From Bitcoin address"}}`
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponderWithQuery("GET", "https://api.coinbase.com/v2/accounts/w-btc/transactions", "limit=25&order=asc",
+		jsonResponder(`{"pagination":{"limit":25,"order":"asc","next_uri":null},"data":[`+tx+`]}`))
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/v2/accounts/w-btc/transactions/t-1", jsonResponder(`{"data":`+tx+`}`))
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/v2/accounts/w-btc/addresses/addr-1/transactions", jsonResponder(`{"pagination":{"next_uri":null},"data":[`+tx+`]}`))
+
+	page, err := api.WalletListTransactions("w-btc", WalletPaginationQuery{Limit: 25, Order: "asc"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if page.Pagination.HasNext() || len(page.Data) != 1 {
+		t.Fatalf("Unexpected page %+v", page)
+	}
+
+	got, err := api.WalletGetTransaction("w-btc", "t-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if got.Type != WalletTransactionTypeSend || got.Status != WalletTransactionStatusCompleted {
+		t.Errorf("Unexpected type or status %+v", got)
+	}
+	if got.Network.Hash != "abc123" || got.Network.Confirmations != 6 || got.NativeAmount.Amount != "650.00" || got.Address.Id != "addr-1" {
+		t.Errorf("Unexpected transaction %+v", got)
+	}
+
+	deposits, err := api.WalletListAddressTransactions("w-btc", "addr-1", WalletPaginationQuery{})
+	if err != nil || len(deposits.Data) != 1 {
+		t.Fatalf("Unexpected address transactions %+v: %v", deposits, err)
+	}
+}
+
+func TestApiClient_WalletAddresses(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	address := `{"id":"addr-1","address":"bc1qexample","address_info":{"address":"bc1qexample"},"name":"treasury","network":"bitcoin","created_at":"2024-05-01T00:00:00Z","updated_at":"2024-05-01T00:00:00Z","resource":"address","resource_path":"/v2/accounts/w-btc/addresses/addr-1","deposit_uri":"bitcoin:bc1qexample"}`
+	var body string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/v2/accounts/w-btc/addresses", jsonResponder(`{"pagination":{"next_uri":null},"data":[`+address+`]}`))
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/v2/accounts/w-btc/addresses/addr-1", jsonResponder(`{"data":`+address+`}`))
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/v2/accounts/w-btc/addresses", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		body = string(b)
+		return jsonResponder(`{"data":` + address + `}`)(request)
+	})
+
+	list, err := api.WalletListAddresses("w-btc", WalletPaginationQuery{})
+	if err != nil || len(list.Data) != 1 {
+		t.Fatalf("Unexpected addresses %+v: %v", list, err)
+	}
+
+	a, err := api.WalletGetAddress("w-btc", "addr-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if a.Address != "bc1qexample" || a.Network != "bitcoin" {
+		t.Errorf("Unexpected address %+v", a)
+	}
+
+	created, err := api.WalletCreateAddress("w-btc", "treasury")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if created.Id != "addr-1" || body != `{"name":"treasury"}` {
+		t.Errorf("Unexpected address %+v for body %s", created, body)
+	}
+}
@@ -0,0 +1,322 @@
+package coinbasev3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrInvalidTransfer = fmt.Errorf("invalid transfer")
+)
+
+type twoFactorTokenKey struct{}
+
+// withTwoFactorToken sends the token in the CB-2FA-TOKEN header of the requests made with the context.
+func withTwoFactorToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, twoFactorTokenKey{}, token)
+}
+
+// twoFactorToken returns the token set with withTwoFactorToken, if any.
+func twoFactorToken(ctx context.Context) string {
+	token, _ := ctx.Value(twoFactorTokenKey{}).(string)
+	return token
+}
+
+type WalletPaymentMethod struct {
+	Id            string         `json:"id"`
+	Type          string         `json:"type"`
+	Name          string         `json:"name"`
+	Currency      string         `json:"currency"`
+	PrimaryBuy    bool           `json:"primary_buy"`
+	PrimarySell   bool           `json:"primary_sell"`
+	AllowBuy      bool           `json:"allow_buy"`
+	AllowSell     bool           `json:"allow_sell"`
+	AllowDeposit  bool           `json:"allow_deposit"`
+	AllowWithdraw bool           `json:"allow_withdraw"`
+	InstantBuy    bool           `json:"instant_buy"`
+	InstantSell   bool           `json:"instant_sell"`
+	FiatAccount   WalletResource `json:"fiat_account"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	Resource      string         `json:"resource"`
+	ResourcePath  string         `json:"resource_path"`
+}
+
+// WalletListPaymentMethods get a page of the user's v2 payment methods, such as bank accounts.
+func (c *ApiClient) WalletListPaymentMethods(q WalletPaginationQuery) (WalletListPaymentMethodsData, error) {
+	return c.WalletListPaymentMethodsWithContext(context.Background(), q)
+}
+
+// WalletListPaymentMethodsWithContext is like WalletListPaymentMethods but binds the request to the given context.
+func (c *ApiClient) WalletListPaymentMethodsWithContext(ctx context.Context, q WalletPaginationQuery) (WalletListPaymentMethodsData, error) {
+	u := c.makeV2Url(fmt.Sprintf("/payment-methods%s", q.BuildQueryString()))
+
+	var data WalletListPaymentMethodsData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+type WalletListPaymentMethodsData struct {
+	Pagination WalletPagination      `json:"pagination"`
+	Data       []WalletPaymentMethod `json:"data"`
+}
+
+// WalletGetPaymentMethod get a v2 payment method, given its id.
+func (c *ApiClient) WalletGetPaymentMethod(paymentMethodId string) (WalletPaymentMethod, error) {
+	return c.WalletGetPaymentMethodWithContext(context.Background(), paymentMethodId)
+}
+
+// WalletGetPaymentMethodWithContext is like WalletGetPaymentMethod but binds the request to the given context.
+func (c *ApiClient) WalletGetPaymentMethodWithContext(ctx context.Context, paymentMethodId string) (WalletPaymentMethod, error) {
+	u := c.makeV2Url(fmt.Sprintf("/payment-methods/%s", paymentMethodId))
+
+	var data WalletPaymentMethodData
+	if err := c.get(ctx, u, &data); err != nil {
+		return data.Data, err
+	}
+	return data.Data, nil
+}
+
+type WalletPaymentMethodData struct {
+	Data WalletPaymentMethod `json:"data"`
+}
+
+// WalletTransferRequest represents a fiat deposit into, or withdrawal from, a v2 wallet account.
+type WalletTransferRequest struct {
+	// AccountId string The fiat wallet account to deposit into or withdraw from.
+	AccountId string `json:"-"`
+	// Amount Decimal The amount to transfer.
+	Amount Decimal `json:"amount"`
+	// Currency string The currency of the amount, e.g. USD.
+	Currency string `json:"currency"`
+	// PaymentMethod string The id of the payment method to transfer from or to.
+	PaymentMethod string `json:"payment_method"`
+	// Commit bool Commit the transfer right away. Otherwise it has to be committed with the matching commit call.
+	Commit bool `json:"commit"`
+	// TwoFactorToken string Optional. Sent in the CB-2FA-TOKEN header when the user has two factor authentication enabled.
+	TwoFactorToken string `json:"-"`
+	// DryRun *DryRunRequest Optional. When set, the request is validated and written to DryRun instead of being sent.
+	DryRun *DryRunRequest `json:"-"`
+}
+
+// Validate checks that the request is complete, without contacting Coinbase.
+func (req WalletTransferRequest) Validate() error {
+	switch {
+	case req.AccountId == "":
+		return fmt.Errorf("%w: account id is required", ErrInvalidTransfer)
+	case req.Amount.Sign() <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidTransfer)
+	case req.Currency == "":
+		return fmt.Errorf("%w: currency is required", ErrInvalidTransfer)
+	case req.PaymentMethod == "":
+		return fmt.Errorf("%w: payment method is required", ErrInvalidTransfer)
+	}
+	return nil
+}
+
+type WalletTransfer struct {
+	Id            string         `json:"id"`
+	Status        string         `json:"status"`
+	PaymentMethod WalletResource `json:"payment_method"`
+	Transaction   WalletResource `json:"transaction"`
+	Amount        WalletMoney    `json:"amount"`
+	Subtotal      WalletMoney    `json:"subtotal"`
+	Fee           WalletMoney    `json:"fee"`
+	Committed     bool           `json:"committed"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	PayoutAt      time.Time      `json:"payout_at"`
+	Resource      string         `json:"resource"`
+	ResourcePath  string         `json:"resource_path"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Required because the payout time is empty until the transfer is committed.
+func (w *WalletTransfer) UnmarshalJSON(data []byte) error {
+	type alias WalletTransfer
+	aux := struct {
+		PayoutAt flexTime `json:"payout_at"`
+		*alias
+	}{alias: (*alias)(w)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	w.PayoutAt = time.Time(aux.PayoutAt)
+	return nil
+}
+
+type WalletTransferData struct {
+	Data WalletTransfer `json:"data"`
+}
+
+// WalletDepositFunds deposit fiat from a payment method into a v2 wallet account. A dry run fills in the request's DryRun and returns an empty transfer.
+func (c *ApiClient) WalletDepositFunds(req WalletTransferRequest) (WalletTransfer, error) {
+	return c.WalletDepositFundsWithContext(context.Background(), req)
+}
+
+// WalletDepositFundsWithContext is like WalletDepositFunds but binds the request to the given context.
+func (c *ApiClient) WalletDepositFundsWithContext(ctx context.Context, req WalletTransferRequest) (WalletTransfer, error) {
+	return c.walletTransfer(ctx, req, "deposits")
+}
+
+// WalletCommitDeposit commit a deposit that was created without Commit.
+func (c *ApiClient) WalletCommitDeposit(accountId, depositId, twoFactorToken string) (WalletTransfer, error) {
+	return c.WalletCommitDepositWithContext(context.Background(), accountId, depositId, twoFactorToken)
+}
+
+// WalletCommitDepositWithContext is like WalletCommitDeposit but binds the request to the given context.
+func (c *ApiClient) WalletCommitDepositWithContext(ctx context.Context, accountId, depositId, twoFactorToken string) (WalletTransfer, error) {
+	return c.walletCommitTransfer(ctx, accountId, "deposits", depositId, twoFactorToken)
+}
+
+// WalletWithdrawFunds withdraw fiat from a v2 wallet account to a payment method. A dry run fills in the request's DryRun and returns an empty transfer.
+func (c *ApiClient) WalletWithdrawFunds(req WalletTransferRequest) (WalletTransfer, error) {
+	return c.WalletWithdrawFundsWithContext(context.Background(), req)
+}
+
+// WalletWithdrawFundsWithContext is like WalletWithdrawFunds but binds the request to the given context.
+func (c *ApiClient) WalletWithdrawFundsWithContext(ctx context.Context, req WalletTransferRequest) (WalletTransfer, error) {
+	return c.walletTransfer(ctx, req, "withdrawals")
+}
+
+// WalletCommitWithdrawal commit a withdrawal that was created without Commit.
+func (c *ApiClient) WalletCommitWithdrawal(accountId, withdrawalId, twoFactorToken string) (WalletTransfer, error) {
+	return c.WalletCommitWithdrawalWithContext(context.Background(), accountId, withdrawalId, twoFactorToken)
+}
+
+// WalletCommitWithdrawalWithContext is like WalletCommitWithdrawal but binds the request to the given context.
+func (c *ApiClient) WalletCommitWithdrawalWithContext(ctx context.Context, accountId, withdrawalId, twoFactorToken string) (WalletTransfer, error) {
+	return c.walletCommitTransfer(ctx, accountId, "withdrawals", withdrawalId, twoFactorToken)
+}
+
+// walletTransfer creates a deposit or withdrawal, depending on the kind.
+func (c *ApiClient) walletTransfer(ctx context.Context, req WalletTransferRequest, kind string) (WalletTransfer, error) {
+	var data WalletTransferData
+
+	if err := req.Validate(); err != nil {
+		return data.Data, err
+	}
+
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s/%s", req.AccountId, kind))
+	body, err := json.Marshal(req)
+	if err != nil {
+		return data.Data, err
+	}
+
+	if req.DryRun != nil {
+		*req.DryRun = DryRunRequest{Method: http.MethodPost, Url: u, Body: body}
+		return data.Data, nil
+	}
+
+	ctx = withTwoFactorToken(ctx, req.TwoFactorToken)
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data.Data, err
+	}
+	return data.Data, nil
+}
+
+// walletCommitTransfer commits a deposit or withdrawal, depending on the kind.
+func (c *ApiClient) walletCommitTransfer(ctx context.Context, accountId, kind, id, token string) (WalletTransfer, error) {
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s/%s/%s/commit", accountId, kind, id))
+
+	// a transfer can only be committed once, so repeating the request cannot transfer twice
+	ctx = withRetrySafe(withTwoFactorToken(ctx, token))
+
+	var data WalletTransferData
+	if err := c.post(ctx, u, nil, &data); err != nil {
+		return data.Data, err
+	}
+	return data.Data, nil
+}
+
+// DryRunRequest is a request that was validated but not sent, because the caller asked for a dry run.
+type DryRunRequest struct {
+	Method string
+	Url    string
+	Body   []byte
+}
+
+// WalletSendMoneyRequest represents a send of crypto to an address or email, or of fiat to a financial institution.
+type WalletSendMoneyRequest struct {
+	// AccountId string The wallet account to send from.
+	AccountId string `json:"-"`
+	// To string A crypto address or email address.
+	To string `json:"to"`
+	// Amount Decimal The amount to send.
+	Amount Decimal `json:"amount"`
+	// Currency string The currency of the amount, e.g. BTC.
+	Currency string `json:"currency"`
+	// Description string Optional. A note for the recipient.
+	Description string `json:"description,omitempty"`
+	// SkipNotifications bool Optional. Don't send notification emails.
+	SkipNotifications bool `json:"skip_notifications,omitempty"`
+	// Idem string Idempotency key that prevents the send from being made twice, e.g. a UUID. Reuse it when repeating a send whose outcome is unknown.
+	Idem string `json:"idem"`
+	// DestinationTag string Optional. The destination tag or memo, for currencies that use one.
+	DestinationTag string `json:"destination_tag,omitempty"`
+	// Network string Optional. The network to send on, e.g. ethereum or base.
+	Network string `json:"network,omitempty"`
+	// TwoFactorToken string Optional. Sent in the CB-2FA-TOKEN header when the user has two factor authentication enabled.
+	TwoFactorToken string `json:"-"`
+	// DryRun *DryRunRequest Optional. When set, the request is validated and written to DryRun instead of being sent.
+	DryRun *DryRunRequest `json:"-"`
+}
+
+// Validate checks that the request is complete, without contacting Coinbase.
+func (req WalletSendMoneyRequest) Validate() error {
+	switch {
+	case req.AccountId == "":
+		return fmt.Errorf("%w: account id is required", ErrInvalidTransfer)
+	case req.To == "":
+		return fmt.Errorf("%w: recipient is required", ErrInvalidTransfer)
+	case req.Amount.Sign() <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidTransfer)
+	case req.Currency == "":
+		return fmt.Errorf("%w: currency is required", ErrInvalidTransfer)
+	case req.Idem == "":
+		return fmt.Errorf("%w: idempotency key is required", ErrInvalidTransfer)
+	}
+	return nil
+}
+
+// WalletSendMoney send funds from a v2 wallet account, e.g. a crypto withdrawal to cold storage. Sending the same request again with the same Idem cannot send twice. When two factor authentication is required without a token, the error matches IsTwoFactorRequired. A dry run fills in the request's DryRun and returns an empty transaction.
+func (c *ApiClient) WalletSendMoney(req WalletSendMoneyRequest) (WalletTransaction, error) {
+	return c.WalletSendMoneyWithContext(context.Background(), req)
+}
+
+// WalletSendMoneyWithContext is like WalletSendMoney but binds the request to the given context.
+func (c *ApiClient) WalletSendMoneyWithContext(ctx context.Context, req WalletSendMoneyRequest) (WalletTransaction, error) {
+	var data WalletTransactionData
+
+	if err := req.Validate(); err != nil {
+		return data.Data, err
+	}
+
+	u := c.makeV2Url(fmt.Sprintf("/accounts/%s/transactions", req.AccountId))
+	body, err := json.Marshal(struct {
+		Type string `json:"type"`
+		WalletSendMoneyRequest
+	}{"send", req})
+	if err != nil {
+		return data.Data, err
+	}
+
+	if req.DryRun != nil {
+		*req.DryRun = DryRunRequest{Method: http.MethodPost, Url: u, Body: body}
+		return data.Data, nil
+	}
+
+	// Coinbase de-duplicates sends by idem, so a retried request cannot send twice
+	ctx = withRetrySafe(withTwoFactorToken(ctx, req.TwoFactorToken))
+	if err := c.post(ctx, u, body, &data); err != nil {
+		return data.Data, err
+	}
+	return data.Data, nil
+}
@@ -0,0 +1,169 @@
+package coinbasev3
+
+import (
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestApiClient_WalletPaymentMethods(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	method := `{"id":"pm-1","type":"ach_bank_account","name":"BANK *****1234","currency":"USD","primary_buy":true,"allow_deposit":true,"allow_withdraw":true,"fiat_account":{"id":"w-usd","resource":"account"},"created_at":"2020-01-01T00:00:00Z"}`
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/v2/payment-methods", jsonResponder(`{"pagination":{"next_uri":null},"data":[`+method+`]}`))
+	httpmock.RegisterResponder("GET", "https://api.coinbase.com/v2/payment-methods/pm-1", jsonResponder(`{"data":`+method+`}`))
+
+	list, err := api.WalletListPaymentMethods(WalletPaginationQuery{})
+	if err != nil || len(list.Data) != 1 {
+		t.Fatalf("Unexpected payment methods %+v: %v", list, err)
+	}
+
+	pm, err := api.WalletGetPaymentMethod("pm-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if pm.Type != "ach_bank_account" || !pm.AllowWithdraw || pm.FiatAccount.Id != "w-usd" {
+		t.Errorf("Unexpected payment method %+v", pm)
+	}
+}
+
+func TestApiClient_WalletDepositAndWithdraw(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	transfer := `{"id":"d-1","status":"created","payment_method":{"id":"pm-1"},"amount":{"amount":"500.00","currency":"USD"},"subtotal":{"amount":"500.00","currency":"USD"},"fee":{"amount":"0.00","currency":"USD"},"committed":%s,"created_at":"2024-05-01T00:00:00Z"}`
+	var depositBody, commitToken string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/v2/accounts/w-usd/deposits", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		depositBody = string(b)
+		return jsonResponder(`{"data":` + strings.Replace(transfer, "%s", "false", 1) + `}`)(request)
+	})
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/v2/accounts/w-usd/deposits/d-1/commit", func(request *http.Request) (*http.Response, error) {
+		commitToken = request.Header.Get("CB-2FA-TOKEN")
+		return jsonResponder(`{"data":` + strings.Replace(transfer, "%s", "true", 1) + `}`)(request)
+	})
+
+	d, err := api.WalletDepositFunds(WalletTransferRequest{AccountId: "w-usd", Amount: "500", Currency: "USD", PaymentMethod: "pm-1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if d.Committed || d.Amount.Amount != "500.00" {
+		t.Errorf("Unexpected deposit %+v", d)
+	}
+	if depositBody != `{"amount":"500","currency":"USD","payment_method":"pm-1","commit":false}` {
+		t.Errorf("Unexpected request body %s", depositBody)
+	}
+
+	d, err = api.WalletCommitDeposit("w-usd", "d-1", "123456")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if !d.Committed || commitToken != "123456" {
+		t.Errorf("Expected a committed deposit with a 2FA token, got %+v and %q", d, commitToken)
+	}
+
+	_, err = api.WalletWithdrawFunds(WalletTransferRequest{AccountId: "w-usd", Amount: "-1", Currency: "USD", PaymentMethod: "pm-1"})
+	if !errors.Is(err, ErrInvalidTransfer) {
+		t.Errorf("Expected ErrInvalidTransfer, got %v", err)
+	}
+}
+
+func TestApiClient_WalletSendMoney(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	var body, token string
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/v2/accounts/w-btc/transactions", func(request *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(request.Body)
+		body = string(b)
+		token = request.Header.Get("CB-2FA-TOKEN")
+		if token == "" {
+			resp := httpmock.NewStringResponse(http.StatusPaymentRequired, `{"errors":[{"id":"two_factor_required","message":"That code was invalid. Please try again."}]}`)
+			resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+			return resp, nil
+		}
+		return jsonResponder(`{"data":{"id":"t-1","type":"send","status":"pending","amount":{"amount":"-0.5","currency":"BTC"},"idem":"idem-1"}}`)(request)
+	})
+
+	req := WalletSendMoneyRequest{AccountId: "w-btc", To: "bc1qcold", Amount: "0.5", Currency: "BTC", Idem: "idem-1"}
+	_, err := api.WalletSendMoney(req)
+	if !IsTwoFactorRequired(err) {
+		t.Fatalf("Expected a two factor error, got %v", err)
+	}
+
+	req.TwoFactorToken = "654321"
+	tx, err := api.WalletSendMoney(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if tx.Status != WalletTransactionStatusPending || token != "654321" {
+		t.Errorf("Unexpected transaction %+v with token %q", tx, token)
+	}
+	if body != `{"type":"send","to":"bc1qcold","amount":"0.5","currency":"BTC","idem":"idem-1"}` {
+		t.Errorf("Unexpected request body %s", body)
+	}
+
+	body = ""
+	req.Idem = ""
+	if _, err := api.WalletSendMoney(req); !errors.Is(err, ErrInvalidTransfer) {
+		t.Fatalf("Expected a missing idempotency key to be rejected, got %v", err)
+	}
+	if body != "" {
+		t.Errorf("Expected no request to be sent, got %s", body)
+	}
+}
+
+func TestApiClient_WalletSendMoney_DryRun(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	calls := 0
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/v2/accounts/w-btc/transactions", func(request *http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponder(`{"data":{}}`)(request)
+	})
+
+	var dry DryRunRequest
+	_, err := api.WalletSendMoney(WalletSendMoneyRequest{AccountId: "w-btc", To: "bc1qcold", Amount: "0.5", Currency: "BTC", Idem: "idem-1", DryRun: &dry})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no request to be sent, got %d", calls)
+	}
+	if dry.Method != http.MethodPost || dry.Url != "https://api.coinbase.com/v2/accounts/w-btc/transactions" {
+		t.Errorf("Unexpected dry run request %s %s", dry.Method, dry.Url)
+	}
+	if want := `{"type":"send","to":"bc1qcold","amount":"0.5","currency":"BTC","idem":"idem-1"}`; string(dry.Body) != want {
+		t.Errorf("Expected body %s, got %s", want, dry.Body)
+	}
+
+	_, err = api.WalletSendMoney(WalletSendMoneyRequest{AccountId: "w-btc", Amount: "0.5", Currency: "BTC", DryRun: &dry})
+	if !errors.Is(err, ErrInvalidTransfer) {
+		t.Errorf("Expected a dry run to validate the request, got %v", err)
+	}
+}
+
+func TestApiClient_WalletWithdrawFunds_DryRun(t *testing.T) {
+	api := NewApiClient("api_key", "secret_key")
+
+	httpmock.ActivateNonDefault(api.client.GetClient())
+	httpmock.RegisterResponder("POST", "https://api.coinbase.com/v2/accounts/w-usd/withdrawals", jsonResponder(`{"data":{}}`))
+	httpmock.ZeroCallCounters()
+
+	var dry DryRunRequest
+	_, err := api.WalletWithdrawFunds(WalletTransferRequest{AccountId: "w-usd", Amount: "100", Currency: "USD", PaymentMethod: "pm-1", DryRun: &dry})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if n := httpmock.GetTotalCallCount(); n != 0 {
+		t.Errorf("Expected no request to be sent, got %d", n)
+	}
+	if dry.Url != "https://api.coinbase.com/v2/accounts/w-usd/withdrawals" || !strings.Contains(string(dry.Body), `"payment_method":"pm-1"`) {
+		t.Errorf("Unexpected dry run request %s %s", dry.Url, dry.Body)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gorilla/websocket"
 	"log"
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -24,16 +25,19 @@ var (
 
 // WsClientConfig is the configuration struct for creating a new websocket client.
 type WsClientConfig struct {
-	Url          string             // optional. defaults to "wss://advanced-trade-ws.coinbase.com"
-	ReadChannel  chan []byte        // required for receiving messages from the websocket connection
-	WsChannels   []WebsocketChannel // required for subscribing to innerChannels on the websocket connection
-	ApiKey       string             // required for signing websocket messages
-	SecretKey    string             // required for signing websocket messages
-	OnConnect    func()             // optional. called when the websocket connection is established
-	OnDisconnect func()             // optional. called when the websocket connection is closed
-	OnReconnect  func()             // optional. called when the websocket connection is re-established
-	UseBackoff   bool               // optional. defaults to false. uses an exponential backoff strategy with jitter
-	Debug        bool               // optional. defaults to false. prints debug messages
+	Url           string             // optional. defaults to "wss://advanced-trade-ws.coinbase.com"
+	ReadChannel   chan []byte        // required for receiving messages from the websocket connection
+	WsChannels    []WebsocketChannel // required for subscribing to innerChannels on the websocket connection
+	ApiKey        string             // required for signing websocket messages unless Authenticator is set
+	SecretKey     string             // required for signing websocket messages unless Authenticator is set
+	Authenticator Authenticator      // optional. signs subscriptions, e.g. with a CdpAuthenticator. defaults to HMAC using ApiKey and SecretKey
+	OnConnect     func()             // optional. called when the websocket connection is established
+	OnDisconnect  func()             // optional. called when the websocket connection is closed
+	OnReconnect   func()             // optional. called when the websocket connection is re-established
+	OnGap         func(SequenceGap)  // optional. called when a message is missing from the sequence of the connection
+	ResyncOnGap   bool               // optional. defaults to false. resubscribes the snapshot based channels (level2 and user) when a message is missing
+	UseBackoff    bool               // optional. defaults to false. uses an exponential backoff strategy with jitter
+	Debug         bool               // optional. defaults to false. prints debug messages
 }
 
 func NewWsClientConfig(apiKey, secretKey string, readCh chan []byte, wsChannels []WebsocketChannel) WsClientConfig {
@@ -49,11 +53,14 @@ func NewWsClientConfig(apiKey, secretKey string, readCh chan []byte, wsChannels
 
 // tryValidate validates the websocket client configuration.
 func (c *WsClientConfig) tryValidate() error {
-	if c.ApiKey == "" {
-		return ErrNoApiKey
-	}
-	if c.SecretKey == "" {
-		return ErrNoSecretKey
+	if c.Authenticator == nil {
+		if c.ApiKey == "" {
+			return ErrNoApiKey
+		}
+		if c.SecretKey == "" {
+			return ErrNoSecretKey
+		}
+		c.Authenticator = NewHmacAuthenticator(c.ApiKey, c.SecretKey)
 	}
 	if c.ReadChannel == nil {
 		return ErrInvalidReadChannel
@@ -81,11 +88,13 @@ type WsClient struct {
 	wsChannels    []WebsocketChannel
 	innerChannels channels
 	cbs           callbacks
+	seq           sequenceTracker
+	resyncOnGap   bool
+	writeMu       sync.Mutex
 	isShutdown    bool
 	useBackoff    bool
 	debug         bool
-	apiKey        string
-	secretKey     string
+	auth          Authenticator
 	ctx           context.Context
 	cancel        context.CancelFunc
 }
@@ -101,6 +110,7 @@ type callbacks struct {
 	onConnect    func()
 	onDisconnect func()
 	onReconnect  func()
+	onGap        func(SequenceGap)
 }
 
 // NewWsClient creates a new websocket client.
@@ -123,14 +133,15 @@ func NewWsClient(cfg WsClientConfig) (*WsClient, error) {
 			onConnect:    cfg.OnConnect,
 			onDisconnect: cfg.OnDisconnect,
 			onReconnect:  cfg.OnReconnect,
+			onGap:        cfg.OnGap,
 		},
-		wsChannels: cfg.WsChannels,
-		apiKey:     cfg.ApiKey,
-		secretKey:  cfg.SecretKey,
-		ctx:        ctx,
-		cancel:     cancel,
-		useBackoff: cfg.UseBackoff,
-		debug:      cfg.Debug,
+		wsChannels:  cfg.WsChannels,
+		auth:        cfg.Authenticator,
+		ctx:         ctx,
+		cancel:      cancel,
+		useBackoff:  cfg.UseBackoff,
+		debug:       cfg.Debug,
+		resyncOnGap: cfg.ResyncOnGap,
 	}
 
 	go c.initReconnectChannel()
@@ -158,6 +169,9 @@ func (c *WsClient) ConnectWithUrl(url string) (*websocket.Conn, error) {
 		return nil, err
 	}
 
+	// sequence numbers restart with every connection
+	c.seq.reset()
+
 	c.cbs.onConnect()
 	go c.read()
 	return conn, nil
@@ -167,7 +181,11 @@ func (c *WsClient) ConnectWithUrl(url string) (*websocket.Conn, error) {
 func (c *WsClient) subscribeToChannels() error {
 	if len(c.wsChannels) > 0 {
 		for ch := range c.wsChannels {
-			err := c.Write(c.wsChannels[ch].marshal(c.apiKey, c.secretKey))
+			msg, err := c.wsChannels[ch].marshal(c.auth)
+			if err != nil {
+				return err
+			}
+			err = c.Write(msg)
 			if err != nil {
 				return err
 			}
@@ -194,6 +212,9 @@ func (c *WsClient) Write(data []byte) error {
 		return ErrNotConnected
 	}
 
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
 	err := c.conn.WriteMessage(websocket.TextMessage, data)
 	if err != nil {
 		return err
@@ -277,6 +298,7 @@ func (c *WsClient) read() {
 			return
 		}
 
+		c.checkSequence(message)
 		c.innerChannels.read <- message
 	}
 }
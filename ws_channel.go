@@ -31,10 +31,11 @@ type WebsocketChannel struct {
 	Type       SubType     `json:"type"`
 	ProductIds []string    `json:"product_ids"`
 	Channel    ChannelType `json:"channel"`
-	Signature  string      `json:"signature"`
-	ApiKey     string      `json:"api_key"`
+	Signature  string      `json:"signature,omitempty"`
+	ApiKey     string      `json:"api_key,omitempty"`
 	SecretKey  string      `json:"-"`
-	Timestamp  string      `json:"timestamp"`
+	Timestamp  string      `json:"timestamp,omitempty"`
+	Jwt        string      `json:"jwt,omitempty"`
 }
 
 func NewWebsocketChannel(subType SubType, channel ChannelType, productIds []string) WebsocketChannel {
@@ -82,19 +83,12 @@ func NewUserChannel(productIds []string) WebsocketChannel {
 	return NewChannelSubscribe(ChannelTypeUser, productIds)
 }
 
-func (s *WebsocketChannel) marshal(apiKey, secretKey string) []byte {
-	s.ApiKey = apiKey
-	s.SecretKey = secretKey
-
-	s.setTimestamp()
-	s.setSignature()
-
-	b, err := json.Marshal(s)
-	if err != nil {
-		return nil
+func (s *WebsocketChannel) marshal(auth Authenticator) ([]byte, error) {
+	if err := auth.AuthenticateChannel(s); err != nil {
+		return nil, err
 	}
 
-	return b
+	return json.Marshal(s)
 }
 
 func (s *WebsocketChannel) setSignature() {
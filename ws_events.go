@@ -2,6 +2,7 @@ package coinbasev3
 
 import (
 	"github.com/mitchellh/mapstructure"
+	"reflect"
 	"time"
 )
 
@@ -14,6 +15,30 @@ type Event struct {
 	Events      []interface{} `json:"events"`
 }
 
+// decodeEvent decodes a generic websocket event into out. Timestamps are sent as RFC 3339 strings, which are parsed into time.Time fields; empty strings leave the field zero.
+func decodeEvent(input interface{}, out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: stringToTimeHook,
+		Result:     out,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(input)
+}
+
+// stringToTimeHook converts RFC 3339 strings into time.Time values.
+func stringToTimeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(time.Time{}) {
+		return data, nil
+	}
+	s, _ := data.(string)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
 // IsTickerEvent returns true if the event is a ticker event.
 // TickerBatch has the same JSON message schema as the ticker channel, except the channel field will have a value of ticker_batch.
 func (e Event) IsTickerEvent() bool {
@@ -32,7 +57,7 @@ func (e Event) GetTickerEvent() (TickerEvent, error) {
 		}
 
 		var event TickerEventType
-		err := mapstructure.Decode(ne, &event)
+		err := decodeEvent(ne, &event)
 		if err != nil {
 			return evt, err
 		}
@@ -84,7 +109,7 @@ func (e Event) GetCandlesEvent() (CandlesEvent, error) {
 		}
 
 		var event CandlesEventType
-		err := mapstructure.Decode(ne, &event)
+		err := decodeEvent(ne, &event)
 		if err != nil {
 			return evt, err
 		}
@@ -112,7 +137,7 @@ func (e Event) GetMarketTradesEvent() (MarketTradesEvent, error) {
 		}
 
 		var event MarketTradesEventType
-		err := mapstructure.Decode(ne, &event)
+		err := decodeEvent(ne, &event)
 		if err != nil {
 			return evt, err
 		}
@@ -140,7 +165,7 @@ func (e Event) GetStatusEvent() (StatusEvent, error) {
 		}
 
 		var event StatusEventType
-		err := mapstructure.Decode(ne, &event)
+		err := decodeEvent(ne, &event)
 		if err != nil {
 			return evt, err
 		}
@@ -168,7 +193,7 @@ func (e Event) GetLevel2Event() (Level2Event, error) {
 		}
 
 		var event Level2EventType
-		err := mapstructure.Decode(ne, &event)
+		err := decodeEvent(ne, &event)
 		if err != nil {
 			return evt, err
 		}
@@ -198,7 +223,7 @@ func (e Event) GetUserEvent() (UserEvent, error) {
 		}
 
 		var event UserEventType
-		err := mapstructure.Decode(ne, &event)
+		err := decodeEvent(ne, &event)
 		if err != nil {
 			return evt, err
 		}
@@ -224,15 +249,15 @@ type TickerEventType struct {
 
 // Ticker represents a ticker from the websocket connection.
 type Ticker struct {
-	Type               string `json:"type" mapstructure:"type"`
-	ProductId          string `json:"product_id" mapstructure:"product_id"`
-	Price              string `json:"price" mapstructure:"price"`
-	Volume24H          string `json:"volume_24_h" mapstructure:"volume_24_h"`
-	Low24H             string `json:"low_24_h" mapstructure:"low_24_h"`
-	High24H            string `json:"high_24_h" mapstructure:"high_24_h"`
-	Low52W             string `json:"low_52_w" mapstructure:"low_52_w"`
-	High52W            string `json:"high_52_w" mapstructure:"high_52_w"`
-	PricePercentChg24H string `json:"price_percent_chg_24_h" mapstructure:"price_percent_chg_24_h"`
+	Type               string  `json:"type" mapstructure:"type"`
+	ProductId          string  `json:"product_id" mapstructure:"product_id"`
+	Price              Decimal `json:"price" mapstructure:"price"`
+	Volume24H          Decimal `json:"volume_24_h" mapstructure:"volume_24_h"`
+	Low24H             Decimal `json:"low_24_h" mapstructure:"low_24_h"`
+	High24H            Decimal `json:"high_24_h" mapstructure:"high_24_h"`
+	Low52W             Decimal `json:"low_52_w" mapstructure:"low_52_w"`
+	High52W            Decimal `json:"high_52_w" mapstructure:"high_52_w"`
+	PricePercentChg24H Decimal `json:"price_percent_chg_24_h" mapstructure:"price_percent_chg_24_h"`
 }
 
 type HeartbeatsEvent struct {
@@ -256,13 +281,13 @@ type CandlesEventType struct {
 }
 
 type Candle struct {
-	Start     string `json:"start" mapstructure:"start"`
-	High      string `json:"high" mapstructure:"high"`
-	Low       string `json:"low" mapstructure:"low"`
-	Open      string `json:"open" mapstructure:"open"`
-	Close     string `json:"close" mapstructure:"close"`
-	Volume    string `json:"volume" mapstructure:"volume"`
-	ProductId string `json:"product_id" mapstructure:"product_id"`
+	Start     string  `json:"start" mapstructure:"start"`
+	High      Decimal `json:"high" mapstructure:"high"`
+	Low       Decimal `json:"low" mapstructure:"low"`
+	Open      Decimal `json:"open" mapstructure:"open"`
+	Close     Decimal `json:"close" mapstructure:"close"`
+	Volume    Decimal `json:"volume" mapstructure:"volume"`
+	ProductId string  `json:"product_id" mapstructure:"product_id"`
 }
 
 type MarketTradesEvent struct {
@@ -278,12 +303,12 @@ type MarketTradesEventType struct {
 type MarketTrade struct {
 	TradeId   string    `json:"trade_id" mapstructure:"trade_id"`
 	ProductId string    `json:"product_id" mapstructure:"product_id"`
-	Price     string    `json:"price" mapstructure:"price"`
-	Size      string    `json:"size" mapstructure:"size"`
+	Price     Decimal   `json:"price" mapstructure:"price"`
+	Size      Decimal   `json:"size" mapstructure:"size"`
 	Side      string    `json:"side" mapstructure:"side"`
 	Time      time.Time `json:"time" mapstructure:"time"`
-	Bid       string    `json:"bid"  mapstructure:"bid"`
-	Ask       string    `json:"ask"  mapstructure:"ask"`
+	Bid       Decimal   `json:"bid"  mapstructure:"bid"`
+	Ask       Decimal   `json:"ask"  mapstructure:"ask"`
 }
 
 type StatusEvent struct {
@@ -297,16 +322,16 @@ type StatusEventType struct {
 }
 
 type ProductStatus struct {
-	ProductType    string `json:"product_type" mapstructure:"product_type"`
-	Id             string `json:"id" mapstructure:"id"`
-	BaseCurrency   string `json:"base_currency" mapstructure:"base_currency"`
-	QuoteCurrency  string `json:"quote_currency" mapstructure:"quote_currency"`
-	BaseIncrement  string `json:"base_increment" mapstructure:"base_increment"`
-	QuoteIncrement string `json:"quote_increment" mapstructure:"quote_increment"`
-	DisplayName    string `json:"display_name" mapstructure:"display_name"`
-	Status         string `json:"status" mapstructure:"status"`
-	StatusMessage  string `json:"status_message" mapstructure:"status_message"`
-	MinMarketFunds string `json:"min_market_funds" mapstructure:"min_market_funds"`
+	ProductType    string  `json:"product_type" mapstructure:"product_type"`
+	Id             string  `json:"id" mapstructure:"id"`
+	BaseCurrency   string  `json:"base_currency" mapstructure:"base_currency"`
+	QuoteCurrency  string  `json:"quote_currency" mapstructure:"quote_currency"`
+	BaseIncrement  Decimal `json:"base_increment" mapstructure:"base_increment"`
+	QuoteIncrement Decimal `json:"quote_increment" mapstructure:"quote_increment"`
+	DisplayName    string  `json:"display_name" mapstructure:"display_name"`
+	Status         string  `json:"status" mapstructure:"status"`
+	StatusMessage  string  `json:"status_message" mapstructure:"status_message"`
+	MinMarketFunds Decimal `json:"min_market_funds" mapstructure:"min_market_funds"`
 }
 
 type Level2Event struct {
@@ -323,8 +348,8 @@ type Level2EventType struct {
 type Level2Update struct {
 	Side        string    `json:"side" mapstructure:"side"`
 	EventTime   time.Time `json:"event_time" mapstructure:"event_time"`
-	PriceLevel  string    `json:"price_level" mapstructure:"price_level"`
-	NewQuantity string    `json:"new_quantity" mapstructure:"new_quantity"`
+	PriceLevel  Decimal   `json:"price_level" mapstructure:"price_level"`
+	NewQuantity Decimal   `json:"new_quantity" mapstructure:"new_quantity"`
 }
 
 type UserEvent struct {
@@ -338,15 +363,29 @@ type UserEventType struct {
 }
 
 type UserOrder struct {
-	OrderId            string    `json:"order_id" mapstructure:"order_id"`
-	ClientOrderId      string    `json:"client_order_id" mapstructure:"client_order_id"`
-	CumulativeQuantity string    `json:"cumulative_quantity" mapstructure:"cumulative_quantity"`
-	LeavesQuantity     string    `json:"leaves_quantity" mapstructure:"leaves_quantity"`
-	AvgPrice           string    `json:"avg_price" mapstructure:"avg_price"`
-	TotalFees          string    `json:"total_fees" mapstructure:"total_fees"`
-	Status             string    `json:"status" mapstructure:"status"`
-	ProductId          string    `json:"product_id" mapstructure:"product_id"`
-	CreationTime       time.Time `json:"creation_time" mapstructure:"creation_time"`
-	OrderSide          string    `json:"order_side" mapstructure:"order_side"`
-	OrderType          string    `json:"order_type" mapstructure:"order_type"`
+	OrderId               string    `json:"order_id" mapstructure:"order_id"`
+	ClientOrderId         string    `json:"client_order_id" mapstructure:"client_order_id"`
+	CumulativeQuantity    Decimal   `json:"cumulative_quantity" mapstructure:"cumulative_quantity"`
+	LeavesQuantity        Decimal   `json:"leaves_quantity" mapstructure:"leaves_quantity"`
+	AvgPrice              Decimal   `json:"avg_price" mapstructure:"avg_price"`
+	TotalFees             Decimal   `json:"total_fees" mapstructure:"total_fees"`
+	Status                string    `json:"status" mapstructure:"status"`
+	ProductId             string    `json:"product_id" mapstructure:"product_id"`
+	CreationTime          time.Time `json:"creation_time" mapstructure:"creation_time"`
+	OrderSide             string    `json:"order_side" mapstructure:"order_side"`
+	OrderType             string    `json:"order_type" mapstructure:"order_type"`
+	LimitPrice            Decimal   `json:"limit_price" mapstructure:"limit_price"`
+	StopPrice             Decimal   `json:"stop_price" mapstructure:"stop_price"`
+	PostOnly              bool      `json:"post_only" mapstructure:"post_only"`
+	TimeInForce           string    `json:"time_in_force" mapstructure:"time_in_force"`
+	TriggerStatus         string    `json:"trigger_status" mapstructure:"trigger_status"`
+	StartTime             time.Time `json:"start_time" mapstructure:"start_time"`
+	EndTime               time.Time `json:"end_time" mapstructure:"end_time"`
+	CompletionPercentage  Decimal   `json:"completion_percentage" mapstructure:"completion_percentage"`
+	FilledValue           Decimal   `json:"filled_value" mapstructure:"filled_value"`
+	NumberOfFills         string    `json:"number_of_fills" mapstructure:"number_of_fills"`
+	OutstandingHoldAmount Decimal   `json:"outstanding_hold_amount" mapstructure:"outstanding_hold_amount"`
+	TotalValueAfterFees   Decimal   `json:"total_value_after_fees" mapstructure:"total_value_after_fees"`
+	CancelReason          string    `json:"cancel_reason" mapstructure:"cancel_reason"`
+	RejectReason          string    `json:"reject_reason" mapstructure:"reject_reason"`
 }
@@ -1,6 +1,7 @@
 package coinbasev3
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -270,3 +271,31 @@ func TestEvent_GetLevel2Event(t *testing.T) {
 		t.Errorf("Expected 21921.73, got %s", ne.Events[0].Updates[0].PriceLevel)
 	}
 }
+
+func TestEvent_GetUserEvent_FromJson(t *testing.T) {
+	msg := `{"channel":"user","client_id":"","timestamp":"2023-11-28T16:00:00.123456Z","sequence_num":3,"events":[{"type":"update","orders":[{"order_id":"XXX","client_order_id":"YYY","cumulative_quantity":"0.5","leaves_quantity":"0.5","avg_price":"30000","total_fees":"1.5","status":"OPEN","product_id":"BTC-USD","creation_time":"2023-11-28T16:00:00.123456Z","order_side":"SELL","order_type":"TWAP","limit_price":"29000","stop_price":"","post_only":false,"time_in_force":"GOOD_UNTIL_DATE_TIME","trigger_status":"INVALID_ORDER_TYPE","start_time":"2023-11-28T16:00:00Z","end_time":"2023-11-28T18:00:00Z","completion_percentage":"50","number_of_fills":"4"}]}]}`
+
+	var evt Event
+	if err := json.Unmarshal([]byte(msg), &evt); err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	ne, err := evt.GetUserEvent()
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	order := ne.Events[0].Orders[0]
+	if order.OrderType != string(OrderTypeTwap) {
+		t.Errorf("Expected TWAP, got %s", order.OrderType)
+	}
+	if order.LimitPrice != "29000" || order.CompletionPercentage != "50" {
+		t.Errorf("Unexpected order %+v", order)
+	}
+	if !order.EndTime.Equal(time.Date(2023, 11, 28, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the end time to be parsed, got %s", order.EndTime)
+	}
+	if order.CreationTime.Nanosecond() != 123456000 {
+		t.Errorf("Expected the creation time to be parsed, got %s", order.CreationTime)
+	}
+}
@@ -0,0 +1,96 @@
+package coinbasev3
+
+import (
+	"encoding/json"
+)
+
+// SequenceGap describes messages missing from the sequence of a websocket connection. Sequence numbers are shared by every channel of a connection, so Channel is the channel of the message that revealed the gap, not necessarily the channel of the missing messages.
+type SequenceGap struct {
+	Channel  string
+	Expected int
+	Received int
+}
+
+// Missed returns the number of missing messages.
+func (g SequenceGap) Missed() int {
+	return g.Received - g.Expected
+}
+
+// sequenceTracker remembers the last sequence number seen on a connection.
+type sequenceTracker struct {
+	last int
+	seen bool
+}
+
+func (t *sequenceTracker) reset() {
+	t.last = 0
+	t.seen = false
+}
+
+// next records a sequence number and reports the gap before it. Sequence numbers at or below the last one are duplicates or arrive late and are ignored.
+func (t *sequenceTracker) next(seq int) (expected int, gap bool) {
+	if !t.seen {
+		t.last, t.seen = seq, true
+		return 0, false
+	}
+	if seq <= t.last {
+		return 0, false
+	}
+
+	expected = t.last + 1
+	t.last = seq
+	return expected, seq > expected
+}
+
+// checkSequence tracks the sequence number of a message, calling OnGap and resyncing when messages are missing. Tracking is skipped unless OnGap or ResyncOnGap is configured.
+func (c *WsClient) checkSequence(message []byte) {
+	if c.cbs.onGap == nil && !c.resyncOnGap {
+		return
+	}
+
+	var evt struct {
+		Channel     string `json:"channel"`
+		SequenceNum *int   `json:"sequence_num"`
+	}
+	if err := json.Unmarshal(message, &evt); err != nil || evt.SequenceNum == nil {
+		return
+	}
+
+	expected, gap := c.seq.next(*evt.SequenceNum)
+	if !gap {
+		return
+	}
+
+	g := SequenceGap{Channel: evt.Channel, Expected: expected, Received: *evt.SequenceNum}
+	c.printf("Sequence gap on %s: expected %d, received %d\n", g.Channel, g.Expected, g.Received)
+	if c.cbs.onGap != nil {
+		c.cbs.onGap(g)
+	}
+	if c.resyncOnGap {
+		if err := c.resync(); err != nil {
+			c.printf("Resync failed: %s\n", err)
+		}
+	}
+}
+
+// resync unsubscribes and resubscribes the level2 and user channels, so Coinbase sends a fresh snapshot of each. The other channels carry no state that a missing message could corrupt.
+func (c *WsClient) resync() error {
+	for _, ch := range c.wsChannels {
+		if ch.Type != SubTypeSubscribe || (ch.Channel != ChannelTypeLevel2 && ch.Channel != ChannelTypeUser) {
+			continue
+		}
+
+		for _, subType := range []SubType{SubTypeUnsubscribe, SubTypeSubscribe} {
+			resub := ch
+			resub.Type = subType
+			msg, err := resub.marshal(c.auth)
+			if err != nil {
+				return err
+			}
+			if err := c.Write(msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
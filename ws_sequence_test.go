@@ -0,0 +1,121 @@
+package coinbasev3
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSequenceTracker_Next(t *testing.T) {
+	var tr sequenceTracker
+	steps := []struct {
+		seq      int
+		expected int
+		gap      bool
+	}{
+		{seq: 5},
+		{seq: 6},
+		{seq: 9, expected: 7, gap: true},
+		{seq: 8},
+		{seq: 10},
+	}
+	for _, s := range steps {
+		expected, gap := tr.next(s.seq)
+		if gap != s.gap || (gap && expected != s.expected) {
+			t.Errorf("next(%d) = %d, %t; want %d, %t", s.seq, expected, gap, s.expected, s.gap)
+		}
+	}
+
+	tr.reset()
+	if _, gap := tr.next(0); gap {
+		t.Error("Expected no gap after a reset")
+	}
+}
+
+func TestWsClient_ResyncOnGap(t *testing.T) {
+	received := make(chan WebsocketChannel, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		go func() {
+			for {
+				var sub WebsocketChannel
+				if err := ws.ReadJSON(&sub); err != nil {
+					return
+				}
+				received <- sub
+			}
+		}()
+
+		for _, seq := range []int{0, 1, 4} {
+			msg := fmt.Sprintf(`{"channel":"l2_data","sequence_num":%d,"events":[]}`, seq)
+			if err := ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+				return
+			}
+		}
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer s.Close()
+
+	gaps := make(chan SequenceGap, 1)
+	chRead := make(chan []byte, 10)
+	cl, err := NewWsClient(WsClientConfig{
+		ApiKey:      "key",
+		SecretKey:   "secret",
+		Url:         makeWsProto(s.URL),
+		ReadChannel: chRead,
+		WsChannels:  []WebsocketChannel{NewLevel2Channel([]string{"BTC-USD"}), NewTickerChannel([]string{"BTC-USD"})},
+		OnGap:       func(g SequenceGap) { gaps <- g },
+		ResyncOnGap: true,
+	})
+	if err != nil {
+		t.Fatalf("NewWsClient: %v", err)
+	}
+	if _, err := cl.Connect(); err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer cl.Shutdown()
+
+	select {
+	case g := <-gaps:
+		if g.Channel != "l2_data" || g.Expected != 2 || g.Received != 4 || g.Missed() != 2 {
+			t.Errorf("Unexpected gap %+v", g)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a sequence gap")
+	}
+
+	var subs []string
+	timeout := time.After(2 * time.Second)
+	for len(subs) < 4 {
+		select {
+		case sub := <-received:
+			subs = append(subs, fmt.Sprintf("%s %s", sub.Type, sub.Channel))
+		case <-timeout:
+			t.Fatalf("Expected 4 subscription messages, got %v", subs)
+		}
+	}
+
+	// the initial subscriptions, then a resubscription of the level2 channel only
+	expected := []string{"subscribe l2_data", "subscribe ticker", "unsubscribe l2_data", "subscribe l2_data"}
+	if a, b := mustJson(subs), mustJson(expected); a != b {
+		t.Errorf("Expected %s, got %s", b, a)
+	}
+
+	for i := 0; i < 3; i++ {
+		<-chRead
+	}
+}
+
+func mustJson(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}